@@ -1,23 +1,99 @@
 package poker
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"time"
 )
 
+// BlindAlerter schedules an alert to be written to to once duration has
+// passed, unless ctx is done first.
 type BlindAlerter interface {
-	ScheduleAlertAt(duration time.Duration, amount int, to io.Writer)
+	ScheduleAlertAt(ctx context.Context, duration time.Duration, alert Alert, to io.Writer)
 }
 
-type BlindAlerterFunc func(duration time.Duration, amount int, to io.Writer)
+type BlindAlerterFunc func(ctx context.Context, duration time.Duration, alert Alert, to io.Writer)
 
-func (b BlindAlerterFunc) ScheduleAlertAt(duration time.Duration, amount int, to io.Writer) {
-	b(duration, amount, to)
+func (b BlindAlerterFunc) ScheduleAlertAt(ctx context.Context, duration time.Duration, alert Alert, to io.Writer) {
+	b(ctx, duration, alert, to)
 }
 
-func Alerter(duration time.Duration, amount int, to io.Writer) {
-	time.AfterFunc(duration, func() {
-		fmt.Fprintf(to, "blind is now %d\n", amount)
-	})
+// AlertKind is what an Alert tells the players.
+type AlertKind int
+
+const (
+	// BlindsUp is that the blinds have gone up to the Level.
+	BlindsUp AlertKind = iota
+	// BlindsWarning is that the blinds go up to the Level In a while.
+	BlindsWarning
+	// BreakStart is that play stops for a Break before the Level, with a
+	// Notice such as which chips to colour up, if there is one.
+	BreakStart
+)
+
+// Alert is something to tell the players during a game.
+type Alert struct {
+	Kind   AlertKind
+	Level  BlindLevel
+	In     time.Duration
+	Break  time.Duration
+	Notice string
+}
+
+func (a Alert) String() string {
+	switch a.Kind {
+	case BlindsWarning:
+		return fmt.Sprintf("%s until blinds go to %v", describeWait(a.In), a.Level)
+	case BreakStart:
+		alert := fmt.Sprintf("break for %s", describeWait(a.Break))
+		if a.Notice != "" {
+			alert += ": " + a.Notice
+		}
+		return alert
+	default:
+		return fmt.Sprintf("blinds are now %v", a.Level)
+	}
+}
+
+// describeWait says how long d is the way people would, like "1 minute".
+func describeWait(d time.Duration) string {
+	plural := func(n int64, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s", unit)
+		}
+		return fmt.Sprintf("%d %ss", n, unit)
+	}
+
+	switch {
+	case d >= time.Minute && d%time.Minute == 0:
+		return plural(int64(d/time.Minute), "minute")
+	case d%time.Second == 0:
+		return plural(int64(d/time.Second), "second")
+	default:
+		return d.String()
+	}
+}
+
+// Alerter writes the alert from a timer on the RealClock, which is stopped
+// when ctx is done.
+func Alerter(ctx context.Context, duration time.Duration, alert Alert, to io.Writer) {
+	AlerterOn(RealClock)(ctx, duration, alert, to)
+}
+
+// AlerterOn is an Alerter whose timers run on clock. Each timer is stopped
+// when ctx is done, until it fires.
+func AlerterOn(clock Clock) BlindAlerterFunc {
+	return func(ctx context.Context, duration time.Duration, alert Alert, to io.Writer) {
+		// stop takes the timer off ctx once it fires. It is sent as soon as
+		// the timer is on ctx, which may be after the timer has fired.
+		stop := make(chan func() bool, 1)
+		timer := clock.AfterFunc(duration, func() {
+			(<-stop)()
+			if ctx.Err() == nil {
+				fmt.Fprintf(to, "%v\n", alert)
+			}
+		})
+		stop <- context.AfterFunc(ctx, func() { timer.Stop() })
+	}
 }
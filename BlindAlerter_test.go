@@ -0,0 +1,64 @@
+package poker_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	poker "github.com/tsugoshi/learn-go-application"
+)
+
+func TestAlerter(t *testing.T) {
+	alert := poker.Alert{Level: poker.BlindLevel{SmallBlind: 100, BigBlind: 200, Ante: 25}}
+
+	t.Run("it writes the alert once the time has passed", func(t *testing.T) {
+		clock := poker.NewFakeClock(time.Now())
+		out := &bytes.Buffer{}
+		poker.AlerterOn(clock)(context.Background(), time.Minute, alert, out)
+
+		clock.Advance(time.Minute - time.Second)
+		if got := out.String(); got != "" {
+			t.Fatalf("got %q written before the time had passed", got)
+		}
+
+		clock.Advance(time.Second)
+		if got, want := out.String(), "blinds are now 100/200, ante 25\n"; got != want {
+			t.Errorf("got %q, wanted %q", got, want)
+		}
+	})
+
+	t.Run("it writes nothing once cancelled", func(t *testing.T) {
+		clock := poker.NewFakeClock(time.Now())
+		out := &bytes.Buffer{}
+		ctx, cancel := context.WithCancel(context.Background())
+		poker.AlerterOn(clock)(ctx, time.Minute, alert, out)
+		cancel()
+
+		clock.Advance(time.Hour)
+		if got := out.String(); got != "" {
+			t.Errorf("got %q written after the alert was cancelled", got)
+		}
+	})
+}
+
+func TestAlert(t *testing.T) {
+	level := poker.BlindLevel{SmallBlind: 200, BigBlind: 400}
+	cases := []struct {
+		alert poker.Alert
+		want  string
+	}{
+		{poker.Alert{Level: level}, "blinds are now 200/400"},
+		{poker.Alert{Kind: poker.BlindsWarning, Level: level, In: time.Minute}, "1 minute until blinds go to 200/400"},
+		{poker.Alert{Kind: poker.BlindsWarning, Level: level, In: 30 * time.Second}, "30 seconds until blinds go to 200/400"},
+		{poker.Alert{Kind: poker.BlindsWarning, Level: level, In: 90 * time.Second}, "90 seconds until blinds go to 200/400"},
+		{poker.Alert{Kind: poker.BreakStart, Level: level, Break: 10 * time.Minute}, "break for 10 minutes"},
+		{poker.Alert{Kind: poker.BreakStart, Level: level, Break: 10 * time.Minute, Notice: "colour up the 25 chips"}, "break for 10 minutes: colour up the 25 chips"},
+	}
+
+	for _, c := range cases {
+		if got := c.alert.String(); got != c.want {
+			t.Errorf("got %q, wanted %q", got, c.want)
+		}
+	}
+}
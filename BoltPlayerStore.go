@@ -0,0 +1,800 @@
+package poker
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	playersBucket = []byte("players")
+	gamesBucket   = []byte("games")
+	seasonsBucket = []byte("seasons")
+	rosterBucket  = []byte("roster")
+	metaBucket    = []byte("meta")
+	// startedBucket holds the key in the games bucket of each game that
+	// hasn't finished, by its ID.
+	startedBucket = []byte("started")
+
+	// namesKey is set in the meta bucket once players are keyed by PlayerKey.
+	namesKey = []byte("names-by-key")
+)
+
+// BoltPlayerStore keeps every game in a bbolt database, along with each
+// player's totals keyed by name. A game and the totals it changes are saved
+// in one transaction and looking up a score is a single key read. It is safe
+// for concurrent use.
+type BoltPlayerStore struct {
+	db *bolt.DB
+
+	// index is the league as of transaction indexTxID, and seasons the
+	// seasons' leagues as of seasonsTxID, so they are only read and sorted
+	// again once the database changes.
+	mu          sync.Mutex
+	index       *leagueIndex
+	indexTxID   int
+	seasons     *seasonIndexes
+	seasonsTxID int
+}
+
+func BoltPlayerStoreFromFile(path string) (*BoltPlayerStore, func(), error) {
+
+	db, err := bolt.Open(path, 0666, &bolt.Options{Timeout: time.Second})
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem opening %s %v", path, err)
+	}
+	closeFunc := func() {
+		db.Close()
+	}
+
+	store, err := NewBoltPlayerStore(db)
+
+	if err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("problem creating bolt player store, %v ", err)
+	}
+
+	return store, closeFunc, nil
+}
+
+func NewBoltPlayerStore(db *bolt.DB) (*BoltPlayerStore, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		if _, err := tx.CreateBucketIfNotExists(playersBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(seasonsBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(rosterBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(metaBucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucketIfNotExists(startedBucket); err != nil {
+			return err
+		}
+		if tx.Bucket(gamesBucket) == nil {
+			if _, err := tx.CreateBucket(gamesBucket); err != nil {
+				return err
+			}
+			if err := addLegacyBoltGames(tx); err != nil {
+				return err
+			}
+		}
+		return keyBoltPlayers(tx)
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("problem initializing database %s, %v", db.Path(), err)
+	}
+	return &BoltPlayerStore{db: db}, nil
+}
+
+func (b *BoltPlayerStore) GetLeague() League {
+	index, err := b.leagueIndex()
+
+	if err != nil {
+		log.Print("Error reading league ", err)
+		return League{}
+	}
+	return append(League{}, index.league...)
+}
+
+// QueryLeague returns the page of the league query asks for.
+func (b *BoltPlayerStore) QueryLeague(query LeagueQuery) (LeaguePage, error) {
+	if query.Season != "" {
+		index, err := b.seasonIndex(query.Season)
+		if err != nil {
+			return LeaguePage{}, err
+		}
+		return index.query(query)
+	}
+
+	index, err := b.leagueIndex()
+	if err != nil {
+		return LeaguePage{}, fmt.Errorf("problem reading league, %v", err)
+	}
+	return index.query(query)
+}
+
+// leagueIndex returns an index of the league as it is now.
+func (b *BoltPlayerStore) leagueIndex() (*leagueIndex, error) {
+	var index *leagueIndex
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if b.index == nil || b.indexTxID != tx.ID() {
+			league, err := getBoltLeague(tx)
+			if err != nil {
+				return err
+			}
+			b.index, b.indexTxID = newLeagueIndex(league.Sorted()), tx.ID()
+		}
+		index = b.index
+		return nil
+	})
+	return index, err
+}
+
+func (b *BoltPlayerStore) GetPlayer(name string) (Player, bool) {
+	var player Player
+	var found bool
+
+	err := b.db.View(func(tx *bolt.Tx) (err error) {
+		found, err = getBoltPlayer(tx, name, &player)
+		if err != nil || found {
+			return err
+		}
+		if registered := tx.Bucket(rosterBucket).Get([]byte(PlayerKey(name))); registered != nil {
+			player, found = NewPlayer(string(registered), 0, 0), true
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error reading player %s %v", name, err)
+		return Player{}, false
+	}
+	return player, found
+}
+
+// GetGames returns every game recorded, oldest first.
+func (b *BoltPlayerStore) GetGames() GameHistory {
+	games := GameHistory{}
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(gamesBucket).ForEach(func(_, v []byte) error {
+			var game GameResult
+			if err := json.Unmarshal(v, &game); err != nil {
+				return err
+			}
+			games = append(games, game)
+			return nil
+		})
+	})
+
+	if err != nil {
+		log.Print("Error reading games ", err)
+		return GameHistory{}
+	}
+	return games
+}
+
+func (b *BoltPlayerStore) RecordWin(name string) error {
+	if err := b.RecordGame(GameResult{Winner: name}); err != nil {
+		return fmt.Errorf("problem saving win for %s, %v", name, err)
+	}
+	return nil
+}
+
+func (b *BoltPlayerStore) RecordGame(result GameResult) error {
+	result, err := completeResult(result)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		_, err := recordBoltGame(tx, result)
+		return err
+	})
+}
+
+// StartGame records the game before it has a result, until RecordGame
+// replaces it with one.
+func (b *BoltPlayerStore) StartGame(game GameResult) (GameResult, error) {
+	game, err := startedGame(game)
+	if err != nil {
+		return game, err
+	}
+
+	err = b.db.Update(func(tx *bolt.Tx) error {
+		game, err = recordBoltGame(tx, game)
+		return err
+	})
+	return game, err
+}
+
+// recordBoltGame adds result to the games and its players' totals, replacing
+// the game with its ID if that was recorded when it started. It returns the
+// result as recorded.
+func recordBoltGame(tx *bolt.Tx, result GameResult) (GameResult, error) {
+	started, startedKey, err := getBoltStarted(tx, result.ID)
+	if err != nil {
+		return result, err
+	}
+
+	seasons, err := getBoltSeasons(tx)
+	if err != nil {
+		return result, err
+	}
+	if startedKey != nil {
+		if result.Season == "" {
+			result.Season = started.Season
+		}
+	} else if season, ok := activeSeason(seasons); ok && result.Season == "" {
+		result.Season = season.ID
+	}
+	names := newBoltNames(tx)
+	result = result.withDisplayNames(names.display)
+	if names.err != nil {
+		return result, names.err
+	}
+
+	tally := newLeagueTally(League{})
+	for _, name := range append(started.Participants(), result.Participants()...) {
+		if tally.find(PlayerKey(name)) != nil {
+			continue
+		}
+		player := NewPlayer(name, 0, 0)
+		if _, err := getBoltPlayer(tx, name, &player); err != nil {
+			return result, err
+		}
+		tally.add(player)
+	}
+
+	if startedKey != nil {
+		if err := tx.Bucket(gamesBucket).Delete(startedKey); err != nil {
+			return result, err
+		}
+		if err := tx.Bucket(startedBucket).Delete([]byte(result.ID)); err != nil {
+			return result, err
+		}
+		tally.unrecord(started)
+	}
+
+	key, err := putBoltGame(tx, result)
+	if err != nil {
+		return result, err
+	}
+	if !result.Finished() {
+		if err := tx.Bucket(startedBucket).Put([]byte(result.ID), key); err != nil {
+			return result, err
+		}
+	}
+
+	tally.record(result)
+	for _, player := range tally.league {
+		if err := putBoltPlayer(tx, player); err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// getBoltStarted returns the unfinished game with the given ID and its key in
+// the games bucket, or a nil key if there isn't one.
+func getBoltStarted(tx *bolt.Tx, id string) (GameResult, []byte, error) {
+	var game GameResult
+	if id == "" {
+		return game, nil, nil
+	}
+	key := tx.Bucket(startedBucket).Get([]byte(id))
+	if key == nil {
+		return game, nil, nil
+	}
+	v := tx.Bucket(gamesBucket).Get(key)
+	if v == nil {
+		return game, nil, nil
+	}
+	return game, append([]byte{}, key...), json.Unmarshal(v, &game)
+}
+
+// ImportGames records the games in a single transaction.
+func (b *BoltPlayerStore) ImportGames(games GameHistory) error {
+	games, err := completeResults(games)
+	if err != nil {
+		return err
+	}
+
+	return b.db.Update(func(tx *bolt.Tx) error {
+		names := newBoltNames(tx)
+		tally := newLeagueTally(League{})
+		for _, game := range games {
+			if game = game.withDisplayNames(names.display); names.err != nil {
+				return names.err
+			}
+			for _, name := range game.Participants() {
+				if tally.find(PlayerKey(name)) != nil {
+					continue
+				}
+				player := NewPlayer(name, 0, 0)
+				if _, err := getBoltPlayer(tx, name, &player); err != nil {
+					return err
+				}
+				tally.add(player)
+			}
+
+			if _, err := putBoltGame(tx, game); err != nil {
+				return err
+			}
+			tally.record(game)
+		}
+
+		for _, player := range tally.league {
+			if err := putBoltPlayer(tx, player); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (b *BoltPlayerStore) StartSeason(id string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		seasons, err := getBoltSeasons(tx)
+		if err != nil {
+			return err
+		}
+
+		seasons, err = startSeason(seasons, id, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+		return putBoltSeasons(tx, seasons)
+	})
+}
+
+func (b *BoltPlayerStore) CloseSeason() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		seasons, err := getBoltSeasons(tx)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := activeSeason(seasons); !ok {
+			return ErrNoActiveSeason
+		}
+		return putBoltSeasons(tx, closeSeason(seasons, time.Now().UTC()))
+	})
+}
+
+// GetSeasons returns every season, in the order they were started.
+func (b *BoltPlayerStore) GetSeasons() []Season {
+	var seasons []Season
+
+	err := b.db.View(func(tx *bolt.Tx) (err error) {
+		seasons, err = getBoltSeasons(tx)
+		return err
+	})
+
+	if err != nil {
+		log.Print("Error reading seasons ", err)
+		return []Season{}
+	}
+	return seasons
+}
+
+// GetSeasonLeague returns the league for the games played in a season.
+func (b *BoltPlayerStore) GetSeasonLeague(id string) (League, error) {
+	index, err := b.seasonIndex(id)
+	if err != nil {
+		return nil, err
+	}
+	return append(League{}, index.league...), nil
+}
+
+// seasonIndex returns an index of the season's league as it is now.
+func (b *BoltPlayerStore) seasonIndex(id string) (*leagueIndex, error) {
+	var index *leagueIndex
+
+	err := b.db.View(func(tx *bolt.Tx) error {
+		seasons, err := getBoltSeasons(tx)
+		if err != nil {
+			return err
+		}
+		if _, ok := findSeason(seasons, id); !ok {
+			return fmt.Errorf("%w: %s", ErrUnknownSeason, id)
+		}
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if b.seasons == nil || b.seasonsTxID != tx.ID() {
+			b.seasons, b.seasonsTxID = newSeasonIndexes(), tx.ID()
+		}
+		index, err = b.seasons.index(id, func() (League, error) {
+			return getBoltSeasonLeague(tx, id)
+		})
+		return err
+	})
+	return index, err
+}
+
+// RenamePlayer changes from's name to to in every game they played.
+func (b *BoltPlayerStore) RenamePlayer(from, to string) error {
+	return b.rewriteGames(
+		func(league League) error { return checkRename(league, from, to, false) },
+		func(game GameResult) (GameResult, bool) { return game.replacePlayer(from, to), true },
+		func(roster []string) []string { return replaceName(roster, from, to) },
+	)
+}
+
+// MergePlayers makes every game from played one into played, so their records
+// are added together under into's name.
+func (b *BoltPlayerStore) MergePlayers(from, into string) error {
+	return b.rewriteGames(
+		func(league League) error { return checkRename(league, from, into, true) },
+		func(game GameResult) (GameResult, bool) { return game.replacePlayer(from, into), true },
+		func(roster []string) []string { return replaceName(roster, from, into) },
+	)
+}
+
+// DeletePlayer takes name out of every game. Players who have won games can
+// only be merged into another player.
+func (b *BoltPlayerStore) DeletePlayer(name string) error {
+	return b.rewriteGames(
+		func(league League) error { return checkDelete(league, name) },
+		func(game GameResult) (GameResult, bool) { return game.withoutPlayer(name), true },
+		func(roster []string) []string { return removeName(roster, name) },
+	)
+}
+
+// rewriteGames changes every game and the roster in one transaction, once
+// check has passed the current league.
+func (b *BoltPlayerStore) rewriteGames(check func(League) error, change func(GameResult) (GameResult, bool), changeRoster func([]string) []string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		league, err := getBoltLeague(tx)
+		if err != nil {
+			return err
+		}
+		if err := check(league); err != nil {
+			return err
+		}
+		return rewriteBoltGames(tx, change, changeRoster)
+	})
+}
+
+// rewriteBoltGames changes every game and the roster, then works the players
+// out again from the result. Games change returns false for are deleted.
+func rewriteBoltGames(tx *bolt.Tx, change func(GameResult) (GameResult, bool), changeRoster func([]string) []string) error {
+	bucket := tx.Bucket(gamesBucket)
+	games := GameHistory{}
+	updates := map[string][]byte{}
+	err := bucket.ForEach(func(k, v []byte) error {
+		var game GameResult
+		if err := json.Unmarshal(v, &game); err != nil {
+			return err
+		}
+
+		game, keep := change(game)
+		if !keep {
+			updates[string(k)] = nil
+			return nil
+		}
+		games = append(games, game)
+
+		updated, err := json.Marshal(game)
+		updates[string(k)] = updated
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	for k, v := range updates {
+		if v == nil {
+			err = bucket.Delete([]byte(k))
+		} else {
+			err = bucket.Put([]byte(k), v)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := tx.DeleteBucket(playersBucket); err != nil {
+		return err
+	}
+	if _, err := tx.CreateBucket(playersBucket); err != nil {
+		return err
+	}
+	for _, player := range games.League() {
+		if err := putBoltPlayer(tx, player); err != nil {
+			return err
+		}
+	}
+
+	roster, err := getBoltRoster(tx)
+	if err != nil {
+		return err
+	}
+	if err := tx.DeleteBucket(rosterBucket); err != nil {
+		return err
+	}
+	if _, err := tx.CreateBucket(rosterBucket); err != nil {
+		return err
+	}
+	for _, name := range changeRoster(roster) {
+		if err := putBoltRoster(tx, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterPlayer puts name on the roster before they have played.
+func (b *BoltPlayerStore) RegisterPlayer(name string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		roster, err := getBoltFullRoster(tx)
+		if err != nil {
+			return err
+		}
+		if err := checkRegistration(roster, name); err != nil {
+			return err
+		}
+		return putBoltRoster(tx, cleanName(name))
+	})
+}
+
+// GetRoster returns everyone registered or in the league, in name order.
+func (b *BoltPlayerStore) GetRoster() []string {
+	var roster []string
+
+	err := b.db.View(func(tx *bolt.Tx) (err error) {
+		roster, err = getBoltFullRoster(tx)
+		return err
+	})
+
+	if err != nil {
+		log.Print("Error reading roster ", err)
+		return []string{}
+	}
+	return roster
+}
+
+// keyBoltPlayers keys players by PlayerKey rather than the name they were
+// recorded under, merging those that differ only in case or spacing. Everyone
+// is renamed to the first name they were recorded under.
+func keyBoltPlayers(tx *bolt.Tx) error {
+	if tx.Bucket(metaBucket).Get(namesKey) != nil {
+		return nil
+	}
+
+	book := nameBook{}
+	err := tx.Bucket(gamesBucket).ForEach(func(_, v []byte) error {
+		var game GameResult
+		if err := json.Unmarshal(v, &game); err != nil {
+			return err
+		}
+		for _, name := range game.Participants() {
+			book.add(name)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	roster, err := getBoltRoster(tx)
+	if err != nil {
+		return err
+	}
+	for _, name := range roster {
+		book.add(name)
+	}
+
+	err = rewriteBoltGames(tx,
+		func(game GameResult) (GameResult, bool) { return game.withDisplayNames(book.display), true },
+		func(roster []string) []string { return displayAll(roster, book.display) },
+	)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(metaBucket).Put(namesKey, []byte("1"))
+}
+
+// addLegacyBoltGames makes up a game for every win saved before games were
+// recorded, so the games bucket holds the whole history.
+func addLegacyBoltGames(tx *bolt.Tx) error {
+	league, err := getBoltLeague(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, game := range legacyGames(league) {
+		if _, err := putBoltGame(tx, game); err != nil {
+			return err
+		}
+	}
+
+	for _, player := range league {
+		if err := putBoltPlayer(tx, NewPlayer(player.Name, player.Wins, player.Wins)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// putBoltGame adds game to the end of the games bucket, returning its key.
+func putBoltGame(tx *bolt.Tx, game GameResult) ([]byte, error) {
+	bucket := tx.Bucket(gamesBucket)
+
+	seq, err := bucket.NextSequence()
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := json.Marshal(game)
+	if err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, seq)
+	return key, bucket.Put(key, v)
+}
+
+func getBoltLeague(tx *bolt.Tx) (League, error) {
+	league := League{}
+	err := tx.Bucket(playersBucket).ForEach(func(_, v []byte) error {
+		var player Player
+		if err := json.Unmarshal(v, &player); err != nil {
+			return err
+		}
+		league = append(league, player)
+		return nil
+	})
+	return league, err
+}
+
+func getBoltPlayer(tx *bolt.Tx, name string, player *Player) (bool, error) {
+	v := tx.Bucket(playersBucket).Get([]byte(PlayerKey(name)))
+	if v == nil {
+		return false, nil
+	}
+	return true, json.Unmarshal(v, player)
+}
+
+func putBoltPlayer(tx *bolt.Tx, player Player) error {
+	v, err := json.Marshal(player)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(playersBucket).Put([]byte(PlayerKey(player.Name)), v)
+}
+
+func getBoltSeasonLeague(tx *bolt.Tx, id string) (League, error) {
+	games := GameHistory{}
+	err := tx.Bucket(gamesBucket).ForEach(func(_, v []byte) error {
+		var game GameResult
+		if err := json.Unmarshal(v, &game); err != nil {
+			return err
+		}
+		if game.Season == id {
+			games = append(games, game)
+		}
+		return nil
+	})
+	return games.League(), err
+}
+
+func getBoltSeasons(tx *bolt.Tx) ([]Season, error) {
+	seasons := []Season{}
+	err := tx.Bucket(seasonsBucket).ForEach(func(_, v []byte) error {
+		var season Season
+		if err := json.Unmarshal(v, &season); err != nil {
+			return err
+		}
+		seasons = append(seasons, season)
+		return nil
+	})
+
+	sort.SliceStable(seasons, func(i, j int) bool {
+		return seasons[i].OpenedAt.Before(seasons[j].OpenedAt)
+	})
+	return seasons, err
+}
+
+func putBoltSeasons(tx *bolt.Tx, seasons []Season) error {
+	for _, season := range seasons {
+		v, err := json.Marshal(season)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(seasonsBucket).Put([]byte(season.ID), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getBoltRoster returns the names registered on the roster. Before players
+// were keyed by PlayerKey the name was the key and there was no value.
+func getBoltRoster(tx *bolt.Tx) ([]string, error) {
+	roster := []string{}
+	err := tx.Bucket(rosterBucket).ForEach(func(k, v []byte) error {
+		if len(v) == 0 {
+			v = k
+		}
+		roster = append(roster, string(v))
+		return nil
+	})
+	return roster, err
+}
+
+func putBoltRoster(tx *bolt.Tx, name string) error {
+	return tx.Bucket(rosterBucket).Put([]byte(PlayerKey(name)), []byte(name))
+}
+
+// boltNames looks up the names players are shown by one at a time, by
+// their PlayerKey, rather than reading every player and the whole roster.
+type boltNames struct {
+	tx   *bolt.Tx
+	book nameBook
+	// err is the first error looking a name up.
+	err error
+}
+
+func newBoltNames(tx *bolt.Tx) *boltNames {
+	return &boltNames{tx: tx, book: nameBook{}}
+}
+
+// display returns the name name is shown by in the league, or on the
+// roster, or as it is first displayed if it is in neither.
+func (n *boltNames) display(name string) string {
+	key := PlayerKey(name)
+	if shown, ok := n.book[key]; ok {
+		return shown
+	}
+
+	shown := cleanName(name)
+	var player Player
+	found, err := getBoltPlayer(n.tx, name, &player)
+	switch {
+	case err != nil:
+		n.err = err
+	case found:
+		shown = player.Name
+	default:
+		if registered := n.tx.Bucket(rosterBucket).Get([]byte(key)); len(registered) > 0 {
+			shown = string(registered)
+		}
+	}
+	if key != "" {
+		n.book[key] = shown
+	}
+	return shown
+}
+
+// getBoltFullRoster returns everyone registered or in the league.
+func getBoltFullRoster(tx *bolt.Tx) ([]string, error) {
+	roster, err := getBoltRoster(tx)
+	if err != nil {
+		return nil, err
+	}
+	league, err := getBoltLeague(tx)
+	if err != nil {
+		return nil, err
+	}
+	return fullRoster(roster, league), nil
+}
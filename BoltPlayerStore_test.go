@@ -0,0 +1,117 @@
+package poker_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	poker "github.com/tsugoshi/learn-go-application"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestBoltStore(t *testing.T) {
+
+	t.Run("league sorted", func(t *testing.T) {
+		store, _ := openBoltStore(t, filepath.Join(t.TempDir(), "game.db"))
+		recordWins(t, store, "Cleo", 1)
+		recordWins(t, store, "Chris", 3)
+
+		want := []poker.Player{
+			poker.NewPlayer("Chris", 3, 3),
+			poker.NewPlayer("Cleo", 1, 1),
+		}
+
+		poker.AssertLeague(t, store.GetLeague(), want)
+	})
+
+	t.Run("get player score", func(t *testing.T) {
+		store, _ := openBoltStore(t, filepath.Join(t.TempDir(), "game.db"))
+		recordWins(t, store, "Chris", 3)
+
+		assertScoreEqual(t, playerWins(store, "Chris"), 3)
+		assertScoreEqual(t, playerWins(store, "Apollo"), 0)
+	})
+
+	t.Run("wins survive reopening the database", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.db")
+		store, closeStore := openBoltStore(t, path)
+		recordWins(t, store, "Cleo", 2)
+		closeStore()
+
+		reopened, _ := openBoltStore(t, path)
+
+		want := []poker.Player{
+			poker.NewPlayer("Cleo", 2, 2),
+		}
+		poker.AssertLeague(t, reopened.GetLeague(), want)
+	})
+
+	t.Run("makes up games for wins saved before games were recorded", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.db")
+
+		db, err := bolt.Open(path, 0666, nil)
+		assertNoError(t, err)
+		err = db.Update(func(tx *bolt.Tx) error {
+			players, err := tx.CreateBucket([]byte("players"))
+			if err != nil {
+				return err
+			}
+			return players.Put([]byte("Cleo"), []byte(`{"Name": "Cleo", "Wins": 2}`))
+		})
+		assertNoError(t, err)
+		db.Close()
+
+		store, _ := openBoltStore(t, path)
+
+		assertScoreEqual(t, playerWins(store, "Cleo"), 2)
+		if games := store.GetGames(); len(games) != 2 {
+			t.Errorf("expected a game for each of the 2 wins, got %v", games)
+		}
+	})
+
+	t.Run("merges players saved under names differing only in case", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "game.db")
+
+		store, closeStore := openBoltStore(t, path)
+		recordWins(t, store, "Chris", 2)
+		closeStore()
+
+		db, err := bolt.Open(path, 0666, nil)
+		assertNoError(t, err)
+		err = db.Update(func(tx *bolt.Tx) error {
+			games := tx.Bucket([]byte("games"))
+			if err := games.Put([]byte("legacy"), []byte(`{"id": "legacy", "winner": "chris "}`)); err != nil {
+				return err
+			}
+			if err := tx.Bucket([]byte("players")).Put([]byte("chris "), []byte(`{"Name": "chris ", "Wins": 1}`)); err != nil {
+				return err
+			}
+			return tx.Bucket([]byte("meta")).Delete([]byte("names-by-key"))
+		})
+		assertNoError(t, err)
+		db.Close()
+
+		store, _ = openBoltStore(t, path)
+
+		poker.AssertLeague(t, store.GetLeague(), []poker.Player{poker.NewPlayer("Chris", 3, 3)})
+		for _, game := range store.GetGames() {
+			if game.Winner != "Chris" {
+				t.Errorf("expected every game to be won by Chris, got %+v", game)
+			}
+		}
+	})
+}
+
+func openBoltStore(t *testing.T, path string) (*poker.BoltPlayerStore, func()) {
+	t.Helper()
+	store, closeStore, err := poker.BoltPlayerStoreFromFile(path)
+	assertNoError(t, err)
+	t.Cleanup(closeStore)
+	return store, closeStore
+}
+
+func recordWins(t *testing.T, store poker.PlayerStore, name string, wins int) {
+	t.Helper()
+	for i := 0; i < wins; i++ {
+		assertNoError(t, store.RecordWin(name))
+	}
+}
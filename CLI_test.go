@@ -3,6 +3,7 @@ package poker_test
 import (
 	"bytes"
 	"io"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -32,6 +33,186 @@ func TestCLI(t *testing.T) {
 
 		poker.AssertGameStartedWith(t, game, 8)
 		poker.AssertFinishCalledWith(t, game, "Cleo")
+		poker.AssertGameCancelled(t, game)
+	})
+
+	t.Run("it cancels the alerts when the input ends without a winner", func(t *testing.T) {
+		game := &poker.GameSpy{}
+		in := userSends("3")
+		cli := poker.NewCLI(game, in, &bytes.Buffer{})
+
+		cli.PlayPoker()
+
+		poker.AssertGameCancelled(t, game)
+	})
+
+	t.Run("it reads the winner however 'wins' is typed", func(t *testing.T) {
+		game := &poker.GameSpy{}
+		in := userSends("3", "Cleo WINS")
+		cli := poker.NewCLI(game, in, &bytes.Buffer{})
+
+		cli.PlayPoker()
+
+		poker.AssertFinishCalledWith(t, game, "Cleo")
+	})
+
+	t.Run("it starts the game with named players", func(t *testing.T) {
+		game := &poker.GameSpy{}
+		in := userSends("Ruth, Cleo, Chris", "Ruth wins")
+		cli := poker.NewCLI(game, in, &bytes.Buffer{})
+
+		cli.PlayPoker()
+
+		poker.AssertGameStartedWith(t, game, 3)
+		want := []string{"Ruth", "Cleo", "Chris"}
+		if !reflect.DeepEqual(game.StartedPlayers, want) {
+			t.Errorf("got players %v, wanted %v", game.StartedPlayers, want)
+		}
+	})
+
+	t.Run("it records the finishing order when given one", func(t *testing.T) {
+		game := &poker.GameSpy{}
+		in := userSends("3", "Ruth, Cleo, Chris")
+		cli := poker.NewCLI(game, in, &bytes.Buffer{})
+
+		cli.PlayPoker()
+
+		poker.AssertFinishCalledWith(t, game, "Ruth")
+		want := []string{"Ruth", "Cleo", "Chris"}
+		if !reflect.DeepEqual(game.FinishedOrder, want) {
+			t.Errorf("got finishing order %v, wanted %v", game.FinishedOrder, want)
+		}
+	})
+
+	t.Run("it prints an error when the winner cannot be recorded", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		in := userSends("3", "Cleo wins")
+		game := &poker.GameSpy{FinishError: poker.ErrWriteFailed}
+
+		cli := poker.NewCLI(game, in, stdout)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerGreeting, poker.BadFinish, poker.ErrWriteFailed.Error())
+	})
+
+	t.Run("it suggests a name from the roster and asks for the result again", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		in := userSends("3", "Chirs wins", "Chris wins")
+
+		store := poker.NewInMemoryPlayerStore()
+		assertNoError(t, store.RegisterPlayer("Chris"))
+		game := poker.NewTexasHoldem(&poker.SpyBlindAlerter{}, poker.NewRosterPlayerStore(store))
+
+		cli := poker.NewCLI(game, in, stdout)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerGreeting, poker.BadFinish,
+			"Chirs is not on the roster, did you mean Chris?\n", poker.RetryFinish)
+		assertScoreEqual(t, playerWins(store, "Chris"), 1)
+	})
+
+	t.Run("it suggests a name from the roster and asks for the players again", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		in := userSends("Cleo, Chirs", "Cleo, Chris", "Chris wins")
+
+		store := poker.NewInMemoryPlayerStore()
+		assertNoError(t, store.RegisterPlayer("Cleo"))
+		assertNoError(t, store.RegisterPlayer("Chris"))
+		game := poker.NewTexasHoldem(&poker.SpyBlindAlerter{}, poker.NewRosterPlayerStore(store))
+
+		cli := poker.NewCLI(game, in, stdout)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout, poker.PlayerGreeting, poker.BadStart,
+			"Chirs is not on the roster, did you mean Chris?\n", poker.RetryStart)
+		games := store.GetGames()
+		if len(games) != 1 || !reflect.DeepEqual(games[0].Players, []string{"Cleo", "Chris"}) || games[0].Winner != "Chris" {
+			t.Errorf("got games %+v, wanted Chris's win over Cleo", games)
+		}
+	})
+
+	t.Run("it starts the game with the blinds chosen", func(t *testing.T) {
+		turbo := poker.BlindStructure{Name: "turbo", Levels: []poker.BlindLevel{{SmallBlind: 25, BigBlind: 50}}}
+		stdout := &bytes.Buffer{}
+		game := &poker.GameSpy{}
+		in := userSends("3", "glacial", "turbo", "Ruth wins")
+
+		cli := poker.NewCLI(game, in, stdout)
+		cli.UseBlindStructures(poker.BlindStructures{"turbo": turbo, "deep": {}})
+		cli.PlayPoker()
+
+		if !reflect.DeepEqual(game.StartedBlinds, turbo) {
+			t.Errorf("got blinds %v, wanted %v", game.StartedBlinds, turbo)
+		}
+		assertMessagesSentToUser(t, stdout,
+			poker.PlayerGreeting,
+			"Blinds: deep, turbo\n",
+			poker.BlindsPrompt,
+			poker.BadBlinds, "no such blind structure: glacial\n",
+			poker.BlindsPrompt,
+		)
+		poker.AssertFinishCalledWith(t, game, "Ruth")
+	})
+
+	t.Run("it starts the game with the default blinds when none is chosen", func(t *testing.T) {
+		game := &poker.GameSpy{}
+		in := userSends("3", "", "Ruth wins")
+
+		cli := poker.NewCLI(game, in, &bytes.Buffer{})
+		cli.UseBlindStructures(poker.BlindStructures{"turbo": {}})
+		cli.PlayPoker()
+
+		if len(game.StartedBlinds.Levels) != 0 {
+			t.Errorf("got blinds %v, wanted the default", game.StartedBlinds)
+		}
+		poker.AssertFinishCalledWith(t, game, "Ruth")
+	})
+
+	t.Run("it adjusts the clock until the result is typed", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		clock := &poker.ClockSpy{Current: poker.ClockStatus{Level: 2, Levels: 3, Blinds: poker.BlindLevel{SmallBlind: 200, BigBlind: 400}}}
+		game := &poker.GameSpy{Clock: clock}
+		in := userSends("3", "pause", "Resume", "next", "back", "add 5m", "Ruth wins")
+
+		cli := poker.NewCLI(game, in, stdout)
+		cli.PlayPoker()
+
+		want := []string{"pause", "resume", "next", "back", "add 5m0s"}
+		if got := clock.CommandsGiven(); !reflect.DeepEqual(got, want) {
+			t.Errorf("got commands %v, wanted %v", got, want)
+		}
+		assertMessagesSentToUser(t, stdout, poker.PlayerGreeting, strings.Repeat("level 2 of 3, blinds 200/400, 0s left\n", 5))
+		poker.AssertFinishCalledWith(t, game, "Ruth")
+	})
+
+	t.Run("it records a winner named like a clock command", func(t *testing.T) {
+		clock := &poker.ClockSpy{}
+		game := &poker.GameSpy{Clock: clock}
+		in := userSends("3", "Next wins")
+
+		cli := poker.NewCLI(game, in, &bytes.Buffer{})
+		cli.PlayPoker()
+
+		if got := clock.CommandsGiven(); len(got) != 0 {
+			t.Errorf("got commands %v, wanted none", got)
+		}
+		poker.AssertFinishCalledWith(t, game, "Next")
+	})
+
+	t.Run("it says when the clock can't be adjusted", func(t *testing.T) {
+		stdout := &bytes.Buffer{}
+		game := &poker.GameSpy{Clock: &poker.ClockSpy{Err: poker.ErrClockPaused}}
+		in := userSends("3", "pause", "add soon", "Ruth wins")
+
+		cli := poker.NewCLI(game, in, stdout)
+		cli.PlayPoker()
+
+		assertMessagesSentToUser(t, stdout,
+			poker.PlayerGreeting,
+			poker.BadClockCommand, "the clock is already paused\n",
+			poker.BadClockCommand, "bad clock command: want a duration like 5m, got \"soon\"\n",
+		)
+		poker.AssertFinishCalledWith(t, game, "Ruth")
 	})
 
 	t.Run("it prints an error when a non numeric value is entered and does not start the game", func(t *testing.T) {
@@ -0,0 +1,478 @@
+package poker
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultSnapshotEvery is how many events an EventLogPlayerStore appends
+// before it writes a snapshot and compacts its log.
+const DefaultSnapshotEvery = 100
+
+// EventLogPlayerStore appends one JSON event per game to a log file and
+// rebuilds the game history by replaying it. Every so often the history is
+// written to a snapshot file next to the log and the log is emptied, so
+// startup only replays the events recorded since the last snapshot. It is
+// safe for concurrent use.
+type EventLogPlayerStore struct {
+	mu sync.RWMutex
+
+	log     *os.File
+	logSize int64
+
+	snapshot       *Tape
+	snapshotEvery  int
+	sinceSnapshot  int
+	lastSequenceNo int
+
+	metadata DatabaseMetadata
+	ledger   *ledger
+}
+
+const (
+	gameEvent        = "game"
+	startEvent       = "game-start"
+	seasonStartEvent = "season-start"
+	seasonCloseEvent = "season-close"
+	renameEvent      = "player-rename"
+	mergeEvent       = "player-merge"
+	deleteEvent      = "player-delete"
+	registerEvent    = "player-register"
+	importEvent      = "games-import"
+)
+
+// logEvent is one line of the log. Logs written before games were recorded
+// have no Type and only name the winner.
+type logEvent struct {
+	Seq    int         `json:"seq"`
+	Type   string      `json:"type,omitempty"`
+	At     time.Time   `json:"at"`
+	Name   string      `json:"name,omitempty"`
+	To     string      `json:"to,omitempty"`
+	Game   *GameResult `json:"game,omitempty"`
+	Games  GameHistory `json:"games,omitempty"`
+	Season string      `json:"season,omitempty"`
+}
+
+// logSnapshot is everything up to and including event Seq. Snapshots taken
+// before games were recorded hold a League instead of a Database.
+type logSnapshot struct {
+	Seq      int             `json:"seq"`
+	Database json.RawMessage `json:"database,omitempty"`
+	League   json.RawMessage `json:"league,omitempty"`
+}
+
+func EventLogPlayerStoreFromFile(path string) (*EventLogPlayerStore, func(), error) {
+
+	db, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("problem opening %s %v", path, err)
+	}
+
+	store, err := NewEventLogPlayerStore(db, DefaultSnapshotEvery)
+
+	if err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("problem creating event log player store, %v ", err)
+	}
+
+	closeFunc := func() {
+		if err := store.Compact(); err != nil {
+			log.Print("Error compacting event log ", err)
+		}
+		db.Close()
+	}
+
+	return store, closeFunc, nil
+}
+
+// NewEventLogPlayerStore replays the snapshot and log to rebuild the game history.
+// The file must have been opened with os.O_APPEND. A snapshot is taken after
+// every snapshotEvery events.
+func NewEventLogPlayerStore(file *os.File, snapshotEvery int) (*EventLogPlayerStore, error) {
+	if snapshotEvery < 1 {
+		return nil, fmt.Errorf("snapshotEvery must be at least 1, got %d", snapshotEvery)
+	}
+
+	store := &EventLogPlayerStore{
+		log:           file,
+		snapshot:      &Tape{Path: file.Name() + ".snapshot"},
+		snapshotEvery: snapshotEvery,
+		metadata:      newDatabase().Metadata,
+		ledger:        newLedger(Database{}),
+	}
+
+	if err := store.loadSnapshot(); err != nil {
+		return nil, err
+	}
+
+	if err := store.replay(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (e *EventLogPlayerStore) loadSnapshot() error {
+	data, err := os.ReadFile(e.snapshot.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("problem reading snapshot %s, %v", e.snapshot.Path, err)
+	}
+
+	var snapshot logSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("problem parsing snapshot %s, %v", e.snapshot.Path, err)
+	}
+
+	saved := snapshot.Database
+	if saved == nil {
+		saved = snapshot.League
+	}
+
+	if saved != nil {
+		db, _, err := readDatabase(bytes.NewReader(saved))
+		if err != nil {
+			return fmt.Errorf("problem loading snapshot %s, %v", e.snapshot.Path, err)
+		}
+		e.metadata = db.Metadata
+		e.ledger = newLedger(db)
+	}
+	e.lastSequenceNo = snapshot.Seq
+	return nil
+}
+
+// replay applies every event in the log that isn't already part of the
+// snapshot. A torn final line, left by a crash part way through an append,
+// is cut off; a bad line anywhere else is an error.
+func (e *EventLogPlayerStore) replay() error {
+	if _, err := e.log.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("problem reading event log %s, %v", e.log.Name(), err)
+	}
+
+	var offset int64
+	reader := bufio.NewReader(e.log)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			if len(bytes.TrimSpace(line)) > 0 {
+				return e.truncateLog(offset)
+			}
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("problem reading event log %s, %v", e.log.Name(), err)
+		}
+
+		var event logEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return fmt.Errorf("problem parsing event at offset %d of %s, %v", offset, e.log.Name(), err)
+		}
+		offset += int64(len(line))
+
+		if event.Seq <= e.lastSequenceNo {
+			continue
+		}
+		if err := applyEvent(e.ledger, event); err != nil {
+			return fmt.Errorf("problem replaying event %d of %s, %v", event.Seq, e.log.Name(), err)
+		}
+		e.lastSequenceNo = event.Seq
+		e.sinceSnapshot++
+	}
+
+	e.logSize = offset
+	return nil
+}
+
+func (e *EventLogPlayerStore) truncateLog(size int64) error {
+	if err := e.log.Truncate(size); err != nil {
+		return fmt.Errorf("problem truncating event log %s, %v", e.log.Name(), err)
+	}
+	e.logSize = size
+	return nil
+}
+
+func applyEvent(l *ledger, event logEvent) error {
+	switch event.Type {
+	case gameEvent, startEvent:
+		if event.Game == nil {
+			return fmt.Errorf("%s event has no game", event.Type)
+		}
+		l.recordGame(*event.Game)
+	case importEvent:
+		l.importGames(event.Games)
+	case seasonStartEvent:
+		return l.startSeason(event.Season, event.At)
+	case seasonCloseEvent:
+		return l.closeSeason(event.At)
+	case renameEvent:
+		return l.renamePlayer(event.Name, event.To)
+	case mergeEvent:
+		return l.mergePlayers(event.Name, event.To)
+	case deleteEvent:
+		return l.deletePlayer(event.Name)
+	case registerEvent:
+		return l.registerPlayer(event.Name)
+	case "":
+		if event.Name == "" {
+			return fmt.Errorf("win event has no winner")
+		}
+		l.recordGame(GameResult{ID: fmt.Sprintf("event-%d", event.Seq), Winner: event.Name, FinishedAt: event.At})
+	default:
+		return fmt.Errorf("unknown event type %q", event.Type)
+	}
+	return nil
+}
+
+func (e *EventLogPlayerStore) GetLeague() League {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.ledger.sortedLeague()
+}
+
+func (e *EventLogPlayerStore) GetPlayer(name string) (Player, bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.ledger.player(name)
+}
+
+// GetGames returns a copy of every game recorded, oldest first.
+func (e *EventLogPlayerStore) GetGames() GameHistory {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return append(GameHistory{}, e.ledger.games...)
+}
+
+func (e *EventLogPlayerStore) RecordWin(name string) error {
+	if err := e.RecordGame(GameResult{Winner: name}); err != nil {
+		return fmt.Errorf("problem saving win for %s, %v", name, err)
+	}
+	return nil
+}
+
+func (e *EventLogPlayerStore) RecordGame(result GameResult) error {
+	result, err := completeResult(result)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// The season is settled now, rather than on replay, so the log holds
+	// exactly what was recorded.
+	result = e.ledger.attribute(result)
+	return e.record(logEvent{Type: gameEvent, Game: &result})
+}
+
+// StartGame appends the game to the log before it has a result. Recording
+// its result replaces it when the log is replayed.
+func (e *EventLogPlayerStore) StartGame(game GameResult) (GameResult, error) {
+	game, err := startedGame(game)
+	if err != nil {
+		return game, err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	game = e.ledger.attribute(game)
+	return game, e.record(logEvent{Type: startEvent, Game: &game})
+}
+
+// ImportGames appends the games to the log as a single event, so they are
+// replayed all together or not at all.
+func (e *EventLogPlayerStore) ImportGames(games GameHistory) error {
+	games, err := completeResults(games)
+	if err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.record(logEvent{Type: importEvent, Games: games})
+}
+
+func (e *EventLogPlayerStore) StartSeason(id string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.record(logEvent{Type: seasonStartEvent, Season: id})
+}
+
+func (e *EventLogPlayerStore) CloseSeason() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.record(logEvent{Type: seasonCloseEvent})
+}
+
+// GetSeasons returns every season, in the order they were started.
+func (e *EventLogPlayerStore) GetSeasons() []Season {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return append([]Season{}, e.ledger.seasons...)
+}
+
+// GetSeasonLeague returns the league for the games played in a season.
+func (e *EventLogPlayerStore) GetSeasonLeague(id string) (League, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.ledger.seasonLeague(id)
+}
+
+// QueryLeague returns the page of the league query asks for.
+func (e *EventLogPlayerStore) QueryLeague(query LeagueQuery) (LeaguePage, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.ledger.queryLeague(query)
+}
+
+// RenamePlayer changes from's name to to in every game they played.
+func (e *EventLogPlayerStore) RenamePlayer(from, to string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.record(logEvent{Type: renameEvent, Name: from, To: to})
+}
+
+// MergePlayers makes every game from played one into played, so their records
+// are added together under into's name.
+func (e *EventLogPlayerStore) MergePlayers(from, into string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.record(logEvent{Type: mergeEvent, Name: from, To: into})
+}
+
+// DeletePlayer takes name out of every game. Players who have won games can
+// only be merged into another player.
+func (e *EventLogPlayerStore) DeletePlayer(name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.record(logEvent{Type: deleteEvent, Name: name})
+}
+
+// RegisterPlayer puts name on the roster before they have played.
+func (e *EventLogPlayerStore) RegisterPlayer(name string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.record(logEvent{Type: registerEvent, Name: name})
+}
+
+// GetRoster returns everyone registered or in the league, in name order.
+func (e *EventLogPlayerStore) GetRoster() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.ledger.fullRoster()
+}
+
+// record applies event to a copy of the ledger and, if that works, appends
+// it to the log before keeping the change. The caller must hold mu.
+func (e *EventLogPlayerStore) record(event logEvent) error {
+	event.Seq = e.lastSequenceNo + 1
+	event.At = time.Now().UTC()
+
+	next := e.ledger.clone()
+	if err := applyEvent(next, event); err != nil {
+		return err
+	}
+
+	if err := e.append(event); err != nil {
+		return err
+	}
+	e.ledger = next
+	e.lastSequenceNo = event.Seq
+	e.sinceSnapshot++
+
+	if e.sinceSnapshot >= e.snapshotEvery {
+		// The event is already durable in the log, so a failed compaction
+		// only means a longer replay next time.
+		if err := e.compact(); err != nil {
+			log.Print("Error compacting event log ", err)
+		}
+	}
+	return nil
+}
+
+// append writes event as a single line and syncs it. If anything goes wrong
+// the log is cut back so a partial line never precedes later events.
+func (e *EventLogPlayerStore) append(event logEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	n, err := e.log.Write(line)
+	if err == nil {
+		err = e.log.Sync()
+	}
+	if err != nil {
+		if n > 0 {
+			e.truncateLog(e.logSize)
+		}
+		return err
+	}
+
+	e.logSize += int64(n)
+	return nil
+}
+
+// Compact writes a snapshot of the game history and empties the log.
+func (e *EventLogPlayerStore) Compact() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.compact()
+}
+
+func (e *EventLogPlayerStore) compact() error {
+	if e.sinceSnapshot == 0 {
+		return nil
+	}
+
+	e.metadata.UpdatedAt = time.Now().UTC()
+	db, err := json.Marshal(e.ledger.database(e.metadata))
+	if err != nil {
+		return fmt.Errorf("problem writing snapshot %s, %v", e.snapshot.Path, err)
+	}
+
+	snapshot := logSnapshot{Seq: e.lastSequenceNo, Database: db}
+	if err := json.NewEncoder(e.snapshot).Encode(snapshot); err != nil {
+		return fmt.Errorf("problem writing snapshot %s, %v", e.snapshot.Path, err)
+	}
+
+	// Events up to the snapshot's sequence number are skipped on replay, so
+	// crashing before the log is emptied is harmless.
+	if err := e.truncateLog(0); err != nil {
+		return err
+	}
+	if err := e.log.Sync(); err != nil {
+		return fmt.Errorf("problem syncing event log %s, %v", e.log.Name(), err)
+	}
+
+	e.sinceSnapshot = 0
+	return nil
+}
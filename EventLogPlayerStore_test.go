@@ -0,0 +1,175 @@
+package poker_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	poker "github.com/tsugoshi/learn-go-application"
+)
+
+func TestEventLogStore(t *testing.T) {
+
+	t.Run("records wins and sorts the league", func(t *testing.T) {
+		store, _ := openEventLogStore(t, filepath.Join(t.TempDir(), "events"), 100)
+
+		assertNoError(t, store.RecordWin("Cleo"))
+		assertNoError(t, store.RecordWin("Chris"))
+		assertNoError(t, store.RecordWin("Chris"))
+
+		want := []poker.Player{
+			poker.NewPlayer("Chris", 2, 2),
+			poker.NewPlayer("Cleo", 1, 1),
+		}
+		poker.AssertLeague(t, store.GetLeague(), want)
+		assertScoreEqual(t, playerWins(store, "Chris"), 2)
+		assertScoreEqual(t, playerWins(store, "Apollo"), 0)
+	})
+
+	t.Run("rebuilds the league by replaying the log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events")
+		store, _ := openEventLogStore(t, path, 100)
+
+		assertNoError(t, store.RecordWin("Cleo"))
+		assertNoError(t, store.RecordWin("Chris"))
+		assertNoError(t, store.RecordWin("Chris"))
+
+		reopened, _ := openEventLogStore(t, path, 100)
+
+		want := []poker.Player{
+			poker.NewPlayer("Chris", 2, 2),
+			poker.NewPlayer("Cleo", 1, 1),
+		}
+		poker.AssertLeague(t, reopened.GetLeague(), want)
+	})
+
+	t.Run("snapshots and compacts the log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events")
+		store, _ := openEventLogStore(t, path, 2)
+
+		assertNoError(t, store.RecordWin("Cleo"))
+		assertNoError(t, store.RecordWin("Chris"))
+		assertFileSize(t, path, 0)
+
+		assertNoError(t, store.RecordWin("Chris"))
+
+		reopened, _ := openEventLogStore(t, path, 2)
+
+		want := []poker.Player{
+			poker.NewPlayer("Chris", 2, 2),
+			poker.NewPlayer("Cleo", 1, 1),
+		}
+		poker.AssertLeague(t, reopened.GetLeague(), want)
+	})
+
+	t.Run("skips events already in the snapshot", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events")
+		writeFile(t, path+".snapshot", `{"seq": 2, "league": [{"Name": "Cleo", "Wins": 2}]}`)
+		writeFile(t, path, `{"seq": 1, "name": "Cleo"}
+{"seq": 2, "name": "Cleo"}
+{"seq": 3, "name": "Chris"}
+`)
+
+		store, _ := openEventLogStore(t, path, 100)
+
+		want := []poker.Player{
+			poker.NewPlayer("Cleo", 2, 2),
+			poker.NewPlayer("Chris", 1, 1),
+		}
+		poker.AssertLeague(t, store.GetLeague(), want)
+	})
+
+	t.Run("reads logs and snapshots from before games were recorded", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events")
+		writeFile(t, path+".snapshot", `{"seq": 1, "league": [{"Name": "Cleo", "Wins": 2}]}`)
+		writeFile(t, path, `{"seq": 2, "name": "Chris", "at": "2026-07-01T19:00:00Z"}
+`)
+
+		store, _ := openEventLogStore(t, path, 100)
+
+		want := []poker.Player{
+			poker.NewPlayer("Cleo", 2, 2),
+			poker.NewPlayer("Chris", 1, 1),
+		}
+		poker.AssertLeague(t, store.GetLeague(), want)
+
+		if games := store.GetGames(); len(games) != 3 {
+			t.Errorf("expected a game for each of the 3 wins, got %v", games)
+		}
+	})
+
+	t.Run("drops a torn final event", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events")
+		writeFile(t, path, `{"seq": 1, "name": "Cleo"}
+{"seq": 2, "na`)
+
+		store, _ := openEventLogStore(t, path, 100)
+		assertNoError(t, store.RecordWin("Chris"))
+
+		reopened, _ := openEventLogStore(t, path, 100)
+
+		want := []poker.Player{
+			poker.NewPlayer("Chris", 1, 1),
+			poker.NewPlayer("Cleo", 1, 1),
+		}
+		poker.AssertLeague(t, reopened.GetLeague(), want)
+	})
+
+	t.Run("rejects a corrupt event in the middle of the log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events")
+		writeFile(t, path, `{"seq": 1, "name": "Cleo"}
+not json
+{"seq": 2, "name": "Chris"}
+`)
+
+		_, _, err := poker.EventLogPlayerStoreFromFile(path)
+		if err == nil {
+			t.Fatal("expected an error opening a corrupt log but didn't get one")
+		}
+	})
+
+	t.Run("closing compacts the log", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "events")
+		store, closeStore := openEventLogStore(t, path, 100)
+
+		assertNoError(t, store.RecordWin("Cleo"))
+		closeStore()
+
+		assertFileSize(t, path, 0)
+
+		reopened, _ := openEventLogStore(t, path, 100)
+		assertScoreEqual(t, playerWins(reopened, "Cleo"), 1)
+	})
+}
+
+func openEventLogStore(t *testing.T, path string, snapshotEvery int) (*poker.EventLogPlayerStore, func()) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	assertNoError(t, err)
+	t.Cleanup(func() { file.Close() })
+
+	store, err := poker.NewEventLogPlayerStore(file, snapshotEvery)
+	assertNoError(t, err)
+
+	closeStore := func() {
+		assertNoError(t, store.Compact())
+		file.Close()
+	}
+	return store, closeStore
+}
+
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0666); err != nil {
+		t.Fatalf("error writing %s, %v", path, err)
+	}
+}
+
+func assertFileSize(t *testing.T, path string, want int64) {
+	t.Helper()
+	info, err := os.Stat(path)
+	assertNoError(t, err)
+	if info.Size() != want {
+		t.Errorf("expected %s to be %d bytes, got %d", path, want, info.Size())
+	}
+}
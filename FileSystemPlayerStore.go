@@ -4,12 +4,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"sort"
+	"sync"
+	"time"
 )
 
+// FileSystemPlayerStore keeps the game history in memory and saves it to a
+// file on every change. Files written by older versions are upgraded when
+// opened. It is safe for concurrent use.
 type FileSystemPlayerStore struct {
-	database *json.Encoder
-	league   League
+	mu       sync.RWMutex
+	tape     *Tape
+	metadata DatabaseMetadata
+	ledger   *ledger
 }
 
 func FileSystemPlayerStoreFromFile(path string) (*FileSystemPlayerStore, func(), error) {
@@ -40,15 +46,24 @@ func NewFileSystemPlayerStore(file *os.File) (*FileSystemPlayerStore, error) {
 		return nil, fmt.Errorf("problem initializing dbFile %s, %v", file.Name(), err)
 	}
 
-	league, err := NewLeague(file)
+	db, migrated, err := readDatabase(file)
 
 	if err != nil {
 		return nil, fmt.Errorf("problem loading player store from file %s, %v", file.Name(), err)
 	}
-	return &FileSystemPlayerStore{
-		database: json.NewEncoder(&Tape{file}),
-		league:   league,
-	}, nil
+
+	store := &FileSystemPlayerStore{
+		tape:     &Tape{Path: file.Name()},
+		metadata: db.Metadata,
+		ledger:   newLedger(db),
+	}
+
+	if migrated {
+		if err := store.save(store.ledger); err != nil {
+			return nil, fmt.Errorf("problem saving upgraded file %s, %v", file.Name(), err)
+		}
+	}
+	return store, nil
 }
 
 func initialiseDBFile(file *os.File) error {
@@ -59,38 +74,187 @@ func initialiseDBFile(file *os.File) error {
 	}
 
 	if info.Size() == 0 {
-		file.Write([]byte("[]"))
+		if err := json.NewEncoder(file).Encode(newDatabase()); err != nil {
+			return fmt.Errorf("problem writing new database to %s, %v", file.Name(), err)
+		}
 		file.Seek(0, 0)
 	}
 	return nil
 }
 
+// GetLeague returns a copy of the league sorted by wins, most first, then by name.
 func (f *FileSystemPlayerStore) GetLeague() League {
-	sort.Slice(f.league, func(i, j int) bool {
-		return f.league[i].Wins > f.league[j].Wins
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.ledger.sortedLeague()
+}
+
+func (f *FileSystemPlayerStore) GetPlayer(name string) (Player, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.ledger.player(name)
+}
+
+// GetGames returns a copy of every game recorded, oldest first.
+func (f *FileSystemPlayerStore) GetGames() GameHistory {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return append(GameHistory{}, f.ledger.games...)
+}
+
+func (f *FileSystemPlayerStore) RecordWin(name string) error {
+	if err := f.RecordGame(GameResult{Winner: name}); err != nil {
+		return fmt.Errorf("problem saving win for %s, %v", name, err)
+	}
+	return nil
+}
+
+func (f *FileSystemPlayerStore) RecordGame(result GameResult) error {
+	result, err := completeResult(result)
+	if err != nil {
+		return err
+	}
+
+	return f.update(func(l *ledger) error {
+		l.recordGame(result)
+		return nil
 	})
-	return f.league
 }
 
-func (f *FileSystemPlayerStore) GetPlayerScore(name string) int {
+// StartGame records the game before it has a result, until RecordGame
+// replaces it with one.
+func (f *FileSystemPlayerStore) StartGame(game GameResult) (GameResult, error) {
+	game, err := startedGame(game)
+	if err != nil {
+		return game, err
+	}
 
-	player := f.league.Find(name)
+	err = f.update(func(l *ledger) error {
+		game = l.recordGame(game)
+		return nil
+	})
+	return game, err
+}
 
-	if player != nil {
-		return player.Wins
+// ImportGames records the games with a single write of the file.
+func (f *FileSystemPlayerStore) ImportGames(games GameHistory) error {
+	games, err := completeResults(games)
+	if err != nil {
+		return err
 	}
 
-	return 0
+	return f.update(func(l *ledger) error {
+		l.importGames(games)
+		return nil
+	})
+}
+
+func (f *FileSystemPlayerStore) StartSeason(id string) error {
+	return f.update(func(l *ledger) error {
+		return l.startSeason(id, time.Now().UTC())
+	})
 }
 
-func (f *FileSystemPlayerStore) RecordWin(name string) {
+func (f *FileSystemPlayerStore) CloseSeason() error {
+	return f.update(func(l *ledger) error {
+		return l.closeSeason(time.Now().UTC())
+	})
+}
 
-	player := f.league.Find(name)
-	if player != nil {
-		player.Wins++
-	} else {
-		f.league = append(f.league, Player{name, 1})
+// GetSeasons returns every season, in the order they were started.
+func (f *FileSystemPlayerStore) GetSeasons() []Season {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return append([]Season{}, f.ledger.seasons...)
+}
+
+// GetSeasonLeague returns the league for the games played in a season.
+func (f *FileSystemPlayerStore) GetSeasonLeague(id string) (League, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.ledger.seasonLeague(id)
+}
+
+// QueryLeague returns the page of the league query asks for.
+func (f *FileSystemPlayerStore) QueryLeague(query LeagueQuery) (LeaguePage, error) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.ledger.queryLeague(query)
+}
+
+// RenamePlayer changes from's name to to in every game they played.
+func (f *FileSystemPlayerStore) RenamePlayer(from, to string) error {
+	return f.update(func(l *ledger) error {
+		return l.renamePlayer(from, to)
+	})
+}
+
+// MergePlayers makes every game from played one into played, so their records
+// are added together under into's name.
+func (f *FileSystemPlayerStore) MergePlayers(from, into string) error {
+	return f.update(func(l *ledger) error {
+		return l.mergePlayers(from, into)
+	})
+}
+
+// DeletePlayer takes name out of every game. Players who have won games can
+// only be merged into another player.
+func (f *FileSystemPlayerStore) DeletePlayer(name string) error {
+	return f.update(func(l *ledger) error {
+		return l.deletePlayer(name)
+	})
+}
+
+// RegisterPlayer puts name on the roster before they have played.
+func (f *FileSystemPlayerStore) RegisterPlayer(name string) error {
+	return f.update(func(l *ledger) error {
+		return l.registerPlayer(name)
+	})
+}
+
+// GetRoster returns everyone registered or in the league, in name order.
+func (f *FileSystemPlayerStore) GetRoster() []string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.ledger.fullRoster()
+}
+
+// update applies change to a copy of the ledger and saves it, only keeping
+// the change if it succeeds and is saved.
+func (f *FileSystemPlayerStore) update(change func(*ledger) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	next := f.ledger.clone()
+	if err := change(next); err != nil {
+		return err
+	}
+
+	if err := f.save(next); err != nil {
+		return err
+	}
+
+	f.ledger = next
+	return nil
+}
+
+// save writes the ledger to disk along with the store's metadata, which is
+// only updated once the write succeeds.
+func (f *FileSystemPlayerStore) save(l *ledger) error {
+	metadata := f.metadata
+	metadata.UpdatedAt = time.Now().UTC()
+
+	if err := json.NewEncoder(f.tape).Encode(l.database(metadata)); err != nil {
+		return err
 	}
 
-	f.database.Encode(f.league)
+	f.metadata = metadata
+	return nil
 }
@@ -1,8 +1,12 @@
 package poker_test
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	poker "github.com/tsugoshi/learn-go-application"
@@ -22,8 +26,8 @@ func TestFileSystemStore(t *testing.T) {
 		assertNoError(t, err)
 
 		want := []poker.Player{
-			{"Chris", 33},
-			{"Cleo", 10},
+			poker.NewPlayer("Chris", 33, 33),
+			poker.NewPlayer("Cleo", 10, 10),
 		}
 
 		got := store.GetLeague()
@@ -43,7 +47,7 @@ func TestFileSystemStore(t *testing.T) {
 		store, err := poker.NewFileSystemPlayerStore(database)
 		assertNoError(t, err)
 
-		got := store.GetPlayerScore("Chris")
+		got := playerWins(store, "Chris")
 		want := 33
 
 		assertScoreEqual(t, got, want)
@@ -59,9 +63,9 @@ func TestFileSystemStore(t *testing.T) {
 		store, err := poker.NewFileSystemPlayerStore(database)
 		assertNoError(t, err)
 
-		store.RecordWin("Chris")
+		assertNoError(t, store.RecordWin("Chris"))
 
-		got := store.GetPlayerScore("Chris")
+		got := playerWins(store, "Chris")
 		want := 34
 
 		assertScoreEqual(t, got, want)
@@ -77,14 +81,88 @@ func TestFileSystemStore(t *testing.T) {
 		store, err := poker.NewFileSystemPlayerStore(database)
 		assertNoError(t, err)
 
-		store.RecordWin("Samantha")
+		assertNoError(t, store.RecordWin("Samantha"))
 
-		got := store.GetPlayerScore("Samantha")
+		got := playerWins(store, "Samantha")
 		want := 1
 		assertScoreEqual(t, got, want)
 
 	})
 
+	t.Run("wins survive reopening the file", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t,
+			`[{"Name": "Cleo", "Wins": 10},
+			{"Name": "Chris", "Wins": 33}]`)
+
+		defer cleanDatabase()
+
+		store, err := poker.NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		assertNoError(t, store.RecordWin("Cleo"))
+
+		got := reopenStore(t, database).GetLeague()
+		want := []poker.Player{
+			poker.NewPlayer("Chris", 33, 33),
+			poker.NewPlayer("Cleo", 11, 11),
+		}
+		poker.AssertLeague(t, got, want)
+	})
+
+	t.Run("a failed write keeps the previous league", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t,
+			`[{"Name": "Cleo", "Wins": 10},
+			{"Name": "Chris", "Wins": 33}]`)
+
+		defer cleanDatabase()
+
+		store, err := poker.NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		poker.FailWritesAfter(store, 5)
+
+		err = store.RecordWin("Chris")
+		if err == nil {
+			t.Fatal("expected an error recording a win but didn't get one")
+		}
+
+		want := []poker.Player{
+			poker.NewPlayer("Chris", 33, 33),
+			poker.NewPlayer("Cleo", 10, 10),
+		}
+		poker.AssertLeague(t, store.GetLeague(), want)
+		poker.AssertLeague(t, reopenStore(t, database).GetLeague(), want)
+
+		leftovers, _ := filepath.Glob(database.Name() + ".tmp-*")
+		if len(leftovers) > 0 {
+			t.Errorf("expected temp files to be cleaned up, found %v", leftovers)
+		}
+	})
+
+	t.Run("a failed write keeps the player being renamed", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t,
+			`[{"Name": "Chirs", "Wins": 2},
+			{"Name": "Chris", "Wins": 33}]`)
+
+		defer cleanDatabase()
+
+		store, err := poker.NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		poker.FailWritesAfter(store, 5)
+
+		if err := store.MergePlayers("Chirs", "Chris"); err == nil {
+			t.Fatal("expected an error merging players but didn't get one")
+		}
+
+		want := []poker.Player{
+			poker.NewPlayer("Chris", 33, 33),
+			poker.NewPlayer("Chirs", 2, 2),
+		}
+		poker.AssertLeague(t, store.GetLeague(), want)
+		poker.AssertLeague(t, reopenStore(t, database).GetLeague(), want)
+	})
+
 	t.Run("works with empty file", func(t *testing.T) {
 		database, cleanDatabase := createTempFile(t, "")
 
@@ -92,9 +170,145 @@ func TestFileSystemStore(t *testing.T) {
 
 		_, err := poker.NewFileSystemPlayerStore(database)
 		assertNoError(t, err)
+
+		got := readDatabaseFile(t, database)
+		assertDatabaseVersion(t, got.Version, poker.DatabaseVersion)
+	})
+
+	t.Run("reads the versioned format", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t,
+			`{"version": 2, "metadata": {}, "players": [{"Name": "Cleo", "Wins": 10}]}`)
+
+		defer cleanDatabase()
+
+		store, err := poker.NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		assertScoreEqual(t, playerWins(store, "Cleo"), 10)
+	})
+
+	t.Run("upgrades a legacy bare league file on open", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t,
+			`[{"Name": "Cleo", "Wins": 10},
+			{"Name": "Chris", "Wins": 33}]`)
+
+		defer cleanDatabase()
+
+		_, err := poker.NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		got := readDatabaseFile(t, database)
+		assertDatabaseVersion(t, got.Version, poker.DatabaseVersion)
+
+		if got.Metadata.MigratedFrom != 1 {
+			t.Errorf("expected database to record migrating from version 1, got %d", got.Metadata.MigratedFrom)
+		}
+
+		if len(got.Games) != 43 {
+			t.Errorf("expected a game for each of the 43 wins, got %d", len(got.Games))
+		}
+
+		want := []poker.Player{
+			poker.NewPlayer("Cleo", 10, 10),
+			poker.NewPlayer("Chris", 33, 33),
+		}
+		poker.AssertLeague(t, got.Games.League(), want)
+	})
+
+	t.Run("adds seasons to a version 3 file on open", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t,
+			`{"version": 3, "metadata": {}, "games": [{"id": "1", "winner": "Cleo"}]}`)
+
+		defer cleanDatabase()
+
+		store, err := poker.NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		got := readDatabaseFile(t, database)
+		assertDatabaseVersion(t, got.Version, poker.DatabaseVersion)
+		if got.Seasons == nil || len(got.Seasons) != 0 {
+			t.Errorf("expected an empty list of seasons, got %v", got.Seasons)
+		}
+		if got.Roster == nil || len(got.Roster) != 0 {
+			t.Errorf("expected an empty roster, got %v", got.Roster)
+		}
+		assertScoreEqual(t, playerWins(store, "Cleo"), 1)
+	})
+
+	t.Run("merges players whose names differ only in case or spacing", func(t *testing.T) {
+		database, cleanDatabase := createTempFile(t,
+			`{"version": 5, "metadata": {}, "roster": ["RUTH", "ruth"], "games": [
+				{"id": "1", "winner": "Chris", "players": ["Chris", "Ruth"]},
+				{"id": "2", "winner": "chris ", "players": ["chris ", "CHRIS"]}
+			]}`)
+
+		defer cleanDatabase()
+
+		store, err := poker.NewFileSystemPlayerStore(database)
+		assertNoError(t, err)
+
+		got := readDatabaseFile(t, database)
+		if want := []string{"Chris"}; !reflect.DeepEqual(got.Games[1].Players, want) {
+			t.Errorf("got players %v, wanted %v", got.Games[1].Players, want)
+		}
+		if want := []string{"Ruth"}; !reflect.DeepEqual(got.Roster, want) {
+			t.Errorf("got roster %v, wanted %v", got.Roster, want)
+		}
+
+		want := []poker.Player{
+			poker.NewPlayer("Chris", 2, 2),
+			poker.NewPlayer("Ruth", 0, 1),
+		}
+		want[0].Rating, want[1].Rating = 1516, 1484
+		poker.AssertLeague(t, store.GetLeague(), want)
+	})
+
+	t.Run("refuses a file from a newer version", func(t *testing.T) {
+		newer := `{"version": 99, "players": [{"Name": "Cleo", "Wins": 10}]}`
+		database, cleanDatabase := createTempFile(t, newer)
+
+		defer cleanDatabase()
+
+		_, err := poker.NewFileSystemPlayerStore(database)
+		if err == nil {
+			t.Fatal("expected an error opening a newer database but didn't get one")
+		}
+		if !strings.Contains(err.Error(), "newer") {
+			t.Errorf("expected error to say the database is newer, got %q", err)
+		}
+
+		contents, _ := ioutil.ReadFile(database.Name())
+		if string(contents) != newer {
+			t.Errorf("expected newer database to be left alone, got %s", contents)
+		}
 	})
 }
 
+func readDatabaseFile(t *testing.T, database *os.File) poker.Database {
+	t.Helper()
+	contents, err := ioutil.ReadFile(database.Name())
+	assertNoError(t, err)
+
+	var db poker.Database
+	if err := json.Unmarshal(contents, &db); err != nil {
+		t.Fatalf("could not parse database %s, %v", contents, err)
+	}
+	return db
+}
+
+func assertDatabaseVersion(t *testing.T, got, want int) {
+	t.Helper()
+	if got != want {
+		t.Errorf("got database version %d, wanted %d", got, want)
+	}
+}
+
+// playerWins is how many games name has won in store, if any.
+func playerWins(store poker.LeagueStore, name string) int {
+	player, _ := store.GetPlayer(name)
+	return player.Wins
+}
+
 func assertScoreEqual(t *testing.T, got, want int) {
 	t.Helper()
 	if got != want {
@@ -123,3 +337,11 @@ func assertNoError(t *testing.T, err error) {
 		t.Fatalf("didn't expect error, but got one: %v", err)
 	}
 }
+
+func reopenStore(t *testing.T, database *os.File) *poker.FileSystemPlayerStore {
+	t.Helper()
+	store, closeStore, err := poker.FileSystemPlayerStoreFromFile(database.Name())
+	assertNoError(t, err)
+	t.Cleanup(closeStore)
+	return store
+}
@@ -0,0 +1,154 @@
+package poker
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// InMemoryPlayerStore keeps the game history in memory only, so it is lost
+// when the program exits. It is safe for concurrent use.
+type InMemoryPlayerStore struct {
+	mu     sync.RWMutex
+	ledger *ledger
+}
+
+func NewInMemoryPlayerStore() *InMemoryPlayerStore {
+	return &InMemoryPlayerStore{ledger: newLedger(Database{})}
+}
+
+func (i *InMemoryPlayerStore) GetPlayer(name string) (Player, bool) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.ledger.player(name)
+}
+
+func (i *InMemoryPlayerStore) RecordWin(name string) error {
+	if err := i.RecordGame(GameResult{Winner: name}); err != nil {
+		return fmt.Errorf("problem saving win for %s, %v", name, err)
+	}
+	return nil
+}
+
+func (i *InMemoryPlayerStore) RecordGame(result GameResult) error {
+	result, err := completeResult(result)
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.ledger.recordGame(result)
+	return nil
+}
+
+func (i *InMemoryPlayerStore) StartGame(game GameResult) (GameResult, error) {
+	game, err := startedGame(game)
+	if err != nil {
+		return game, err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.ledger.recordGame(game), nil
+}
+
+func (i *InMemoryPlayerStore) ImportGames(games GameHistory) error {
+	games, err := completeResults(games)
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.ledger.importGames(games)
+	return nil
+}
+
+func (i *InMemoryPlayerStore) GetLeague() League {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.ledger.sortedLeague()
+}
+
+func (i *InMemoryPlayerStore) GetGames() GameHistory {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return append(GameHistory{}, i.ledger.games...)
+}
+
+func (i *InMemoryPlayerStore) StartSeason(id string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.ledger.startSeason(id, time.Now().UTC())
+}
+
+func (i *InMemoryPlayerStore) CloseSeason() error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.ledger.closeSeason(time.Now().UTC())
+}
+
+func (i *InMemoryPlayerStore) GetSeasons() []Season {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return append([]Season{}, i.ledger.seasons...)
+}
+
+func (i *InMemoryPlayerStore) GetSeasonLeague(id string) (League, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.ledger.seasonLeague(id)
+}
+
+func (i *InMemoryPlayerStore) QueryLeague(query LeagueQuery) (LeaguePage, error) {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.ledger.queryLeague(query)
+}
+
+func (i *InMemoryPlayerStore) RenamePlayer(from, to string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.ledger.renamePlayer(from, to)
+}
+
+func (i *InMemoryPlayerStore) MergePlayers(from, into string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.ledger.mergePlayers(from, into)
+}
+
+func (i *InMemoryPlayerStore) DeletePlayer(name string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.ledger.deletePlayer(name)
+}
+
+func (i *InMemoryPlayerStore) RegisterPlayer(name string) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.ledger.registerPlayer(name)
+}
+
+func (i *InMemoryPlayerStore) GetRoster() []string {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	return i.ledger.fullRoster()
+}
@@ -0,0 +1,351 @@
+package poker
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// APIVersion prefixes every route of the JSON API described by OpenAPI.
+const APIVersion = "/api/v1"
+
+// OpenAPI is the OpenAPI description of the routes under APIVersion.
+//
+//go:embed openapi.json
+var OpenAPI []byte
+
+// APIError is the body of every error response from the API.
+type APIError struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// NewSession is posted to start a live game, naming its players if known.
+// Blinds names the blind structure to play with, if not the default.
+type NewSession struct {
+	NumberOfPlayers int      `json:"numberOfPlayers"`
+	Players         []string `json:"players,omitempty"`
+	Blinds          string   `json:"blinds,omitempty"`
+}
+
+// SessionResult is posted to finish a live game, winner first.
+type SessionResult struct {
+	FinishingOrder []string `json:"finishingOrder"`
+}
+
+// Session is the game being played live through the API.
+type Session struct {
+	NumberOfPlayers int       `json:"numberOfPlayers"`
+	Players         []string  `json:"players,omitempty"`
+	Blinds          string    `json:"blinds,omitempty"`
+	StartedAt       time.Time `json:"startedAt"`
+	// Alerts are the blind alerts sent so far, oldest first.
+	Alerts []string `json:"alerts"`
+}
+
+// session holds the live game, if there is one.
+type session struct {
+	mu      sync.Mutex
+	current *Session
+	game    Tournament
+	alerts  alertLog
+	// cancel abandons the live game, stopping its alerts.
+	cancel context.CancelFunc
+}
+
+// end forgets the live game, cancelling any alerts it has left. It must be
+// called with mu held.
+func (s *session) end() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.current = nil
+	s.game = nil
+	s.cancel = nil
+}
+
+// alertLog collects a game's blind alerts as they are sent.
+type alertLog struct {
+	mu     sync.Mutex
+	alerts strings.Builder
+}
+
+func (a *alertLog) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.alerts.Write(p)
+}
+
+// lines are the alerts sent so far, oldest first.
+func (a *alertLog) lines() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	lines := []string{}
+	for _, line := range strings.Split(a.alerts.String(), "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func (a *alertLog) reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.alerts.Reset()
+}
+
+// apiRoutes are the routes of the API, relative to APIVersion.
+func (p *PlayerServer) apiRoutes() *http.ServeMux {
+	router := http.NewServeMux()
+	router.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("no such route %s", r.URL.Path))
+	}))
+	router.Handle("/openapi.json", p.apiGet(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("content-type", "application/json")
+		w.Write(OpenAPI)
+	}))
+	router.Handle("/league", p.apiGet(p.apiLeague))
+	router.Handle("/players", p.apiGet(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, p.store.GetLeague())
+	}))
+	router.Handle("/players/", http.HandlerFunc(p.apiPlayer))
+	router.Handle("/games", http.HandlerFunc(p.apiGames))
+	router.Handle("/session", http.HandlerFunc(p.apiSession))
+	router.Handle("/session/finish", http.HandlerFunc(p.apiFinishSession))
+	return router
+}
+
+// apiGet only lets GET requests through to handler.
+func (p *PlayerServer) apiGet(handler http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if allowAPIMethods(w, r, http.MethodGet) {
+			handler(w, r)
+		}
+	})
+}
+
+func (p *PlayerServer) apiLeague(w http.ResponseWriter, r *http.Request) {
+	page, status, err := p.queryLeague(w, r.URL.Query())
+	if err != nil {
+		writeAPIError(w, status, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, page.Players)
+}
+
+// apiPlayer serves /players/{name}, and the player's history and stats, and
+// records their wins.
+func (p *PlayerServer) apiPlayer(w http.ResponseWriter, r *http.Request) {
+	name, resource, ok := parsePlayerPath(r.URL.EscapedPath(), "history", "stats", "wins")
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("no such route %s", r.URL.Path))
+		return
+	}
+
+	if resource == "wins" {
+		if allowAPIMethods(w, r, http.MethodPost) {
+			p.apiRecordWin(w, name)
+		}
+		return
+	}
+
+	if !allowAPIMethods(w, r, http.MethodGet) {
+		return
+	}
+	player, ok := p.store.GetPlayer(name)
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, fmt.Errorf("%w: %s", ErrUnknownPlayer, name))
+		return
+	}
+
+	switch resource {
+	case "history":
+		writeJSON(w, http.StatusOK, p.store.GetGames().ForPlayer(name))
+	default:
+		writeJSON(w, http.StatusOK, player)
+	}
+}
+
+func (p *PlayerServer) apiRecordWin(w http.ResponseWriter, name string) {
+	if err := p.store.RecordWin(name); err != nil {
+		writeAPIError(w, storeErrorStatus(err), err)
+		return
+	}
+	player, _ := p.store.GetPlayer(name)
+	writeJSON(w, http.StatusOK, player)
+}
+
+// apiGames lists the games played, or records one that is posted.
+func (p *PlayerServer) apiGames(w http.ResponseWriter, r *http.Request) {
+	if !allowAPIMethods(w, r, http.MethodGet, http.MethodPost) {
+		return
+	}
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, p.store.GetGames())
+		return
+	}
+
+	var result GameResult
+	if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("problem parsing game, %v", err))
+		return
+	}
+	result, err := completeResult(result)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err := p.store.RecordGame(result); err != nil {
+		writeAPIError(w, storeErrorStatus(err), err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, result)
+}
+
+// apiSession shows the live game, starts one when a NewSession is posted,
+// or abandons it when deleted.
+func (p *PlayerServer) apiSession(w http.ResponseWriter, r *http.Request) {
+	if !allowAPIMethods(w, r, http.MethodGet, http.MethodPost, http.MethodDelete) {
+		return
+	}
+
+	p.session.mu.Lock()
+	defer p.session.mu.Unlock()
+
+	if r.Method == http.MethodDelete {
+		if p.session.current == nil {
+			writeAPIError(w, http.StatusNotFound, errors.New("no game is being played"))
+			return
+		}
+		p.session.end()
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		if p.session.current == nil {
+			writeAPIError(w, http.StatusNotFound, errors.New("no game is being played"))
+			return
+		}
+		current := *p.session.current
+		current.Alerts = p.session.alerts.lines()
+		writeJSON(w, http.StatusOK, current)
+		return
+	}
+
+	var start NewSession
+	if err := json.NewDecoder(r.Body).Decode(&start); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("problem parsing session, %v", err))
+		return
+	}
+	if len(start.Players) > 0 {
+		start.NumberOfPlayers = len(start.Players)
+	}
+	if start.NumberOfPlayers < 2 {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("a game needs at least two players, got %d", start.NumberOfPlayers))
+		return
+	}
+	blinds, err := p.blinds.Find(start.Blinds)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, err)
+		return
+	}
+	if p.session.current != nil {
+		writeAPIError(w, http.StatusConflict, errors.New("a game is already being played"))
+		return
+	}
+
+	p.session.alerts.reset()
+	p.session.current = &Session{
+		NumberOfPlayers: start.NumberOfPlayers,
+		Players:         start.Players,
+		Blinds:          start.Blinds,
+		StartedAt:       p.clock.Now().UTC(),
+	}
+	var ctx context.Context
+	ctx, p.session.cancel = context.WithCancel(context.Background())
+	if p.session.game, err = p.game.Start(ctx, start.NumberOfPlayers, blinds, &p.session.alerts, start.Players...); err != nil {
+		p.session.end()
+		writeAPIError(w, storeErrorStatus(err), err)
+		return
+	}
+
+	current := *p.session.current
+	current.Alerts = p.session.alerts.lines()
+	writeJSON(w, http.StatusCreated, current)
+}
+
+// apiFinishSession finishes the live game with the posted SessionResult.
+func (p *PlayerServer) apiFinishSession(w http.ResponseWriter, r *http.Request) {
+	if !allowAPIMethods(w, r, http.MethodPost) {
+		return
+	}
+
+	var result SessionResult
+	if err := json.NewDecoder(r.Body).Decode(&result); err != nil {
+		writeAPIError(w, http.StatusBadRequest, fmt.Errorf("problem parsing result, %v", err))
+		return
+	}
+	if len(result.FinishingOrder) == 0 || strings.TrimSpace(result.FinishingOrder[0]) == "" {
+		writeAPIError(w, http.StatusBadRequest, errors.New("finishing order needs a winner"))
+		return
+	}
+
+	p.session.mu.Lock()
+	defer p.session.mu.Unlock()
+	if p.session.current == nil {
+		writeAPIError(w, http.StatusConflict, errors.New("no game is being played"))
+		return
+	}
+
+	if err := p.session.game.Finish(result.FinishingOrder[0], result.FinishingOrder[1:]...); err != nil {
+		writeAPIError(w, storeErrorStatus(err), err)
+		return
+	}
+	p.session.end()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// storeErrorStatus is the status to report an error recording a game with.
+func storeErrorStatus(err error) int {
+	if errors.Is(err, ErrNotOnRoster) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+// allowAPIMethods reports whether r uses one of methods, writing a 405 if it
+// doesn't.
+func allowAPIMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, method := range methods {
+		if r.Method == method {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeAPIError(w, http.StatusMethodNotAllowed, fmt.Errorf("%s is not allowed here", r.Method))
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("content-type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeAPIError writes err as an APIError with the given status. Its code is
+// the status text in snake case, like "not_found".
+func writeAPIError(w http.ResponseWriter, status int, err error) {
+	code := strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+	writeJSON(w, status, APIError{Status: status, Code: code, Message: err.Error()})
+}
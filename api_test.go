@@ -0,0 +1,378 @@
+package poker_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	poker "github.com/tsugoshi/learn-go-application"
+)
+
+func TestAPI(t *testing.T) {
+	var spec openAPISpec
+	if err := json.Unmarshal(poker.OpenAPI, &spec); err != nil {
+		t.Fatalf("problem parsing OpenAPI description, %v", err)
+	}
+
+	cases := []struct {
+		name       string
+		operation  string
+		before     []*http.Request
+		request    *http.Request
+		roster     bool
+		wantStatus int
+	}{
+		{name: "league", operation: "getLeague", request: newAPIRequest(http.MethodGet, "/league", ""), wantStatus: http.StatusOK},
+		{name: "league by rating", operation: "getLeague", request: newAPIRequest(http.MethodGet, "/league?sort=rating", ""), wantStatus: http.StatusOK},
+		{name: "league page", operation: "getLeague", request: newAPIRequest(http.MethodGet, "/league?sort=name&prefix=p&limit=1", ""), wantStatus: http.StatusOK},
+		{name: "league by unknown order", operation: "getLeague", request: newAPIRequest(http.MethodGet, "/league?sort=luck", ""), wantStatus: http.StatusBadRequest},
+		{name: "league for unknown season", operation: "getLeague", request: newAPIRequest(http.MethodGet, "/league?season=1999-Q1", ""), wantStatus: http.StatusNotFound},
+		{name: "players", operation: "listPlayers", request: newAPIRequest(http.MethodGet, "/players", ""), wantStatus: http.StatusOK},
+		{name: "player", operation: "getPlayer", request: newAPIRequest(http.MethodGet, "/players/Pepper", ""), wantStatus: http.StatusOK},
+		{name: "unknown player", operation: "getPlayer", request: newAPIRequest(http.MethodGet, "/players/Apollo", ""), wantStatus: http.StatusNotFound},
+		{name: "player stats", operation: "getPlayerStats", request: newAPIRequest(http.MethodGet, "/players/pepper/stats", ""), wantStatus: http.StatusOK},
+		{name: "player history", operation: "getPlayerHistory", request: newAPIRequest(http.MethodGet, "/players/Pepper/history", ""), wantStatus: http.StatusOK},
+		{name: "unknown player's history", operation: "getPlayerHistory", request: newAPIRequest(http.MethodGet, "/players/Apollo/history", ""), wantStatus: http.StatusNotFound},
+		{name: "win", operation: "recordWin", request: newAPIRequest(http.MethodPost, "/players/Pepper/wins", ""), wantStatus: http.StatusOK},
+		{name: "win for player not on roster", operation: "recordWin", roster: true, request: newAPIRequest(http.MethodPost, "/players/Peper/wins", ""), wantStatus: http.StatusNotFound},
+		{name: "games", operation: "listGames", request: newAPIRequest(http.MethodGet, "/games", ""), wantStatus: http.StatusOK},
+		{name: "record game", operation: "recordGame", request: newAPIRequest(http.MethodPost, "/games", `{"winner": "Floyd", "players": ["Floyd", "Pepper"]}`), wantStatus: http.StatusCreated},
+		{name: "record game without winner", operation: "recordGame", request: newAPIRequest(http.MethodPost, "/games", `{"players": ["Floyd", "Pepper"]}`), wantStatus: http.StatusBadRequest},
+		{name: "record game with player not on roster", operation: "recordGame", roster: true, request: newAPIRequest(http.MethodPost, "/games", `{"winner": "Floid"}`), wantStatus: http.StatusNotFound},
+		{name: "no session", operation: "getSession", request: newAPIRequest(http.MethodGet, "/session", ""), wantStatus: http.StatusNotFound},
+		{
+			name:       "session",
+			operation:  "getSession",
+			before:     []*http.Request{newAPIRequest(http.MethodPost, "/session", `{"numberOfPlayers": 3}`)},
+			request:    newAPIRequest(http.MethodGet, "/session", ""),
+			wantStatus: http.StatusOK,
+		},
+		{name: "start session", operation: "startSession", request: newAPIRequest(http.MethodPost, "/session", `{"players": ["Floyd", "Pepper"]}`), wantStatus: http.StatusCreated},
+		{name: "start session with player not on roster", operation: "startSession", roster: true, request: newAPIRequest(http.MethodPost, "/session", `{"players": ["Floyd", "Peper"]}`), wantStatus: http.StatusNotFound},
+		{name: "start session for one", operation: "startSession", request: newAPIRequest(http.MethodPost, "/session", `{"numberOfPlayers": 1}`), wantStatus: http.StatusBadRequest},
+		{
+			name:       "abandon session",
+			operation:  "abandonSession",
+			before:     []*http.Request{newAPIRequest(http.MethodPost, "/session", `{"numberOfPlayers": 3}`)},
+			request:    newAPIRequest(http.MethodDelete, "/session", ""),
+			wantStatus: http.StatusNoContent,
+		},
+		{name: "abandon no session", operation: "abandonSession", request: newAPIRequest(http.MethodDelete, "/session", ""), wantStatus: http.StatusNotFound},
+		{name: "start session with unknown blinds", operation: "startSession", request: newAPIRequest(http.MethodPost, "/session", `{"numberOfPlayers": 3, "blinds": "glacial"}`), wantStatus: http.StatusBadRequest},
+		{
+			name:       "start second session",
+			operation:  "startSession",
+			before:     []*http.Request{newAPIRequest(http.MethodPost, "/session", `{"numberOfPlayers": 3}`)},
+			request:    newAPIRequest(http.MethodPost, "/session", `{"numberOfPlayers": 3}`),
+			wantStatus: http.StatusConflict,
+		},
+		{
+			name:       "finish session",
+			operation:  "finishSession",
+			before:     []*http.Request{newAPIRequest(http.MethodPost, "/session", `{"numberOfPlayers": 3}`)},
+			request:    newAPIRequest(http.MethodPost, "/session/finish", `{"finishingOrder": ["Pepper", "Floyd"]}`),
+			wantStatus: http.StatusNoContent,
+		},
+		{name: "finish session without winner", operation: "finishSession", request: newAPIRequest(http.MethodPost, "/session/finish", `{"finishingOrder": []}`), wantStatus: http.StatusBadRequest},
+		{name: "finish session that never started", operation: "finishSession", request: newAPIRequest(http.MethodPost, "/session/finish", `{"finishingOrder": ["Pepper"]}`), wantStatus: http.StatusConflict},
+		{name: "OpenAPI description", operation: "getOpenAPI", request: newAPIRequest(http.MethodGet, "/openapi.json", ""), wantStatus: http.StatusOK},
+	}
+
+	tested := map[string]bool{}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			store := &poker.StubPlayerStore{
+				Scores: map[string]int{"Pepper": 2, "Floyd": 1},
+				League: poker.League{poker.NewPlayer("Pepper", 2, 3), poker.NewPlayer("Floyd", 1, 3)},
+				Games:  poker.GameHistory{{ID: "a1", Winner: "Pepper", Players: []string{"Pepper", "Floyd"}}},
+			}
+			var playerStore poker.PlayerStore = store
+			if c.roster {
+				playerStore = poker.NewRosterPlayerStore(store)
+			}
+			game := poker.NewTexasHoldem(dummyBlindAlerter, playerStore)
+			server := poker.MustMakePlayerServer(t, playerStore, game)
+
+			for _, request := range c.before {
+				server.ServeHTTP(httptest.NewRecorder(), request)
+			}
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, c.request)
+
+			poker.AssertStatus(t, response.Code, c.wantStatus)
+
+			path, method, documented := spec.find(c.operation)
+			if !documented {
+				t.Fatalf("operation %s is not in the OpenAPI description", c.operation)
+			}
+			if !matchesTemplate(c.request.URL.Path, poker.APIVersion+path) || c.request.Method != strings.ToUpper(method) {
+				t.Fatalf("%s %s is not operation %s, %s %s", c.request.Method, c.request.URL.Path, c.operation, method, path)
+			}
+			tested[c.operation] = true
+
+			documentedResponse, ok := spec.Paths[path][method].Responses[strconv.Itoa(response.Code)]
+			if !ok {
+				t.Fatalf("status %d is not documented for %s", response.Code, c.operation)
+			}
+			documentedResponse = spec.resolveResponse(documentedResponse)
+			media, ok := documentedResponse.Content["application/json"]
+			if !ok {
+				if response.Body.Len() > 0 {
+					t.Errorf("got body %q for %s, which is documented without one", response.Body, c.operation)
+				}
+				return
+			}
+
+			poker.AssertContentType(t, response, poker.JsonContentType)
+			var body interface{}
+			if err := json.Unmarshal(response.Body.Bytes(), &body); err != nil {
+				t.Fatalf("problem parsing response %q, %v", response.Body, err)
+			}
+			if err := spec.validate(media.Schema, body, "body"); err != "" {
+				t.Errorf("response doesn't match the OpenAPI description: %s\n%s", err, response.Body)
+			}
+		})
+	}
+
+	t.Run("every operation is tested", func(t *testing.T) {
+		for path, methods := range spec.Paths {
+			for method, operation := range methods {
+				if method != "parameters" && !tested[operation.OperationID] {
+					t.Errorf("no test for %s %s", strings.ToUpper(method), path)
+				}
+			}
+		}
+	})
+
+	t.Run("errors are JSON", func(t *testing.T) {
+		server := poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+		for _, request := range []*http.Request{
+			newAPIRequest(http.MethodGet, "/nowhere", ""),
+			newAPIRequest(http.MethodGet, "/players/Pepper/wins", ""),
+			newAPIRequest(http.MethodDelete, "/games", ""),
+		} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, request)
+
+			var apiErr poker.APIError
+			if err := json.Unmarshal(response.Body.Bytes(), &apiErr); err != nil {
+				t.Fatalf("problem parsing error %q, %v", response.Body, err)
+			}
+			if apiErr.Status != response.Code || apiErr.Code == "" || apiErr.Message == "" {
+				t.Errorf("got error %+v for %s %s", apiErr, request.Method, request.URL)
+			}
+		}
+	})
+
+	t.Run("method not allowed says which are", func(t *testing.T) {
+		server := poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newAPIRequest(http.MethodDelete, "/games", ""))
+
+		poker.AssertStatus(t, response.Code, http.StatusMethodNotAllowed)
+		if got := response.Header().Get("Allow"); got != "GET, POST" {
+			t.Errorf("got Allow %q, want %q", got, "GET, POST")
+		}
+		if !strings.Contains(response.Body.String(), `"code":"method_not_allowed"`) {
+			t.Errorf("got %s, want a method_not_allowed error", response.Body)
+		}
+	})
+
+	t.Run("finishing or abandoning the session cancels its alerts", func(t *testing.T) {
+		for _, end := range []*http.Request{
+			newAPIRequest(http.MethodPost, "/session/finish", `{"finishingOrder": ["Pepper"]}`),
+			newAPIRequest(http.MethodDelete, "/session", ""),
+		} {
+			game := &poker.GameSpy{}
+			server := poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, game)
+
+			server.ServeHTTP(httptest.NewRecorder(), newAPIRequest(http.MethodPost, "/session", `{"numberOfPlayers": 3}`))
+			if game.StartedContext.Err() != nil {
+				t.Fatal("alerts were cancelled as soon as the session started")
+			}
+
+			server.ServeHTTP(httptest.NewRecorder(), end)
+			if game.StartedContext.Err() == nil {
+				t.Errorf("alerts were not cancelled by %s %s", end.Method, end.URL)
+			}
+		}
+	})
+
+	t.Run("the session starts on the server's clock", func(t *testing.T) {
+		startedAt := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+		server := poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, &poker.GameSpy{})
+		server.UseClock(poker.NewFakeClock(startedAt))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newAPIRequest(http.MethodPost, "/session", `{"numberOfPlayers": 3}`))
+
+		var session poker.Session
+		json.Unmarshal(response.Body.Bytes(), &session)
+		if !session.StartedAt.Equal(startedAt) {
+			t.Errorf("got session started at %v, wanted %v", session.StartedAt, startedAt)
+		}
+	})
+
+	t.Run("is served for each league", func(t *testing.T) {
+		server := poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+		store := &poker.StubPlayerStore{Scores: map[string]int{"Cleo": 4}}
+		if err := server.AddLeague("office", store, dummyGame); err != nil {
+			t.Fatal(err)
+		}
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest(t, "/leagues/office"+poker.APIVersion+"/players/Cleo"))
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		var player poker.Player
+		json.Unmarshal(response.Body.Bytes(), &player)
+		if player.Name != "Cleo" || player.Wins != 4 {
+			t.Errorf("got %+v, want Cleo with 4 wins", player)
+		}
+	})
+}
+
+var dummyBlindAlerter = &poker.SpyBlindAlerter{}
+
+func newAPIRequest(method, path, body string) *http.Request {
+	request, _ := http.NewRequest(method, poker.APIVersion+path, strings.NewReader(body))
+	return request
+}
+
+// matchesTemplate reports whether path fits an OpenAPI path template like
+// /players/{name}.
+func matchesTemplate(path, template string) bool {
+	got, want := strings.Split(path, "/"), strings.Split(template, "/")
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range want {
+		if !strings.HasPrefix(want[i], "{") && got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// openAPISpec is as much of an OpenAPI description as TestAPI checks the
+// handlers against.
+type openAPISpec struct {
+	Paths      map[string]map[string]openAPIOperation `json:"paths"`
+	Components struct {
+		Responses map[string]openAPIResponse `json:"responses"`
+		Schemas   map[string]*openAPISchema  `json:"schemas"`
+	} `json:"components"`
+}
+
+type openAPIOperation struct {
+	OperationID string                     `json:"operationId"`
+	Responses   map[string]openAPIResponse `json:"responses"`
+}
+
+// UnmarshalJSON lets a path's parameters sit alongside its operations.
+func (o *openAPIOperation) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '[' {
+		return nil
+	}
+	type operation openAPIOperation
+	return json.Unmarshal(data, (*operation)(o))
+}
+
+type openAPIResponse struct {
+	Ref     string `json:"$ref"`
+	Content map[string]struct {
+		Schema *openAPISchema `json:"schema"`
+	} `json:"content"`
+}
+
+type openAPISchema struct {
+	Ref        string                    `json:"$ref"`
+	Type       string                    `json:"type"`
+	Required   []string                  `json:"required"`
+	Properties map[string]*openAPISchema `json:"properties"`
+	Items      *openAPISchema            `json:"items"`
+	Enum       []interface{}             `json:"enum"`
+}
+
+func (s openAPISpec) find(operationID string) (path, method string, ok bool) {
+	for path, methods := range s.Paths {
+		for method, operation := range methods {
+			if operation.OperationID == operationID {
+				return path, method, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+func (s openAPISpec) resolveResponse(response openAPIResponse) openAPIResponse {
+	if response.Ref == "" {
+		return response
+	}
+	return s.Components.Responses[strings.TrimPrefix(response.Ref, "#/components/responses/")]
+}
+
+// validate returns what is wrong with value according to schema, or "" if
+// nothing is.
+func (s openAPISpec) validate(schema *openAPISchema, value interface{}, at string) string {
+	if schema.Ref != "" {
+		ref, ok := s.Components.Schemas[strings.TrimPrefix(schema.Ref, "#/components/schemas/")]
+		if !ok {
+			return at + ": unknown schema " + schema.Ref
+		}
+		return s.validate(ref, value, at)
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return at + ": want an object"
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				return at + ": missing " + name
+			}
+		}
+		for name, field := range object {
+			property, ok := schema.Properties[name]
+			if !ok {
+				if len(schema.Properties) > 0 {
+					return at + ": undocumented property " + name
+				}
+				continue
+			}
+			if err := s.validate(property, field, at+"."+name); err != "" {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]interface{})
+		if !ok {
+			return at + ": want an array"
+		}
+		for _, item := range array {
+			if err := s.validate(schema.Items, item, at+"[]"); err != "" {
+				return err
+			}
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			return at + ": want a string"
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return at + ": want a number"
+		}
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int64(n)) {
+			return at + ": want an integer"
+		}
+	}
+	return ""
+}
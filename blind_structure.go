@@ -0,0 +1,259 @@
+package poker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrBadBlindStructure     = errors.New("bad blind structure")
+	ErrUnknownBlindStructure = errors.New("no such blind structure")
+)
+
+// YAMLFormat is the format of blind structures written in YAML. They can
+// also be written in JSONFormat.
+const YAMLFormat = "yaml"
+
+// BlindStructure is how the blinds go up over a game, one level after
+// another.
+type BlindStructure struct {
+	Name   string       `json:"name" yaml:"name"`
+	Levels []BlindLevel `json:"levels" yaml:"levels"`
+	// Breaks are when play stops for a break, if it does.
+	Breaks *Breaks `json:"breaks,omitempty" yaml:"breaks,omitempty"`
+	// Warning is how long before each level to warn that the blinds are
+	// going up. There are no warnings without it.
+	Warning Duration `json:"warning,omitempty" yaml:"warning,omitempty"`
+}
+
+// Breaks stop play for a while every so many levels.
+type Breaks struct {
+	Every    int      `json:"every" yaml:"every"`
+	Duration Duration `json:"duration" yaml:"duration"`
+	// Notices are told to the players at each break in turn, like "colour
+	// up the 25 chips". Breaks after they run out have none.
+	Notices []string `json:"notices,omitempty" yaml:"notices,omitempty"`
+}
+
+// BlindLevel is one level of a BlindStructure. The last level lasts until
+// the game finishes, so it needn't have a Duration.
+type BlindLevel struct {
+	SmallBlind int      `json:"smallBlind" yaml:"smallBlind"`
+	BigBlind   int      `json:"bigBlind" yaml:"bigBlind"`
+	Ante       int      `json:"ante,omitempty" yaml:"ante,omitempty"`
+	Duration   Duration `json:"duration" yaml:"duration"`
+}
+
+func (l BlindLevel) String() string {
+	if l.Ante > 0 {
+		return fmt.Sprintf("%d/%d, ante %d", l.SmallBlind, l.BigBlind, l.Ante)
+	}
+	return fmt.Sprintf("%d/%d", l.SmallBlind, l.BigBlind)
+}
+
+// Duration is a time.Duration written like "15m" in blind structures.
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"15m\", got %s", data)
+	}
+	return d.parse(s)
+}
+
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	return d.parse(value.Value)
+}
+
+func (d *Duration) parse(s string) error {
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("duration must be like \"15m\", got %q", s)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// DefaultBlindStructure is the structure games are played with unless
+// another is chosen: blinds from 100 to 8000, going up every 5 minutes plus
+// a minute for each player.
+func DefaultBlindStructure(numberOfPlayers int) BlindStructure {
+	blinds := []int{100, 200, 300, 400, 500, 600, 800, 1000, 2000, 4000, 8000}
+	blindIncrement := Duration(time.Duration(5+numberOfPlayers) * time.Minute)
+
+	structure := BlindStructure{Name: "default"}
+	for _, blind := range blinds {
+		structure.Levels = append(structure.Levels, BlindLevel{SmallBlind: blind, BigBlind: 2 * blind, Duration: blindIncrement})
+	}
+	return structure
+}
+
+// Validate reports what, if anything, is wrong with the structure.
+func (s BlindStructure) Validate() error {
+	named := ""
+	if s.Name != "" {
+		named = " " + s.Name
+	}
+	if len(s.Levels) == 0 {
+		return fmt.Errorf("%w%s: it has no levels", ErrBadBlindStructure, named)
+	}
+	if s.Warning < 0 {
+		return fmt.Errorf("%w%s: warning can't be negative, got %v", ErrBadBlindStructure, named, time.Duration(s.Warning))
+	}
+	if b := s.Breaks; b != nil {
+		if b.Every <= 0 {
+			return fmt.Errorf("%w%s: breaks must come every 1 or more levels, got %d", ErrBadBlindStructure, named, b.Every)
+		}
+		if b.Duration <= 0 {
+			return fmt.Errorf("%w%s: breaks must last more than 0, got %v", ErrBadBlindStructure, named, time.Duration(b.Duration))
+		}
+	}
+
+	for i, level := range s.Levels {
+		var problem string
+		switch {
+		case level.SmallBlind <= 0:
+			problem = fmt.Sprintf("small blind must be more than 0, got %d", level.SmallBlind)
+		case level.BigBlind < level.SmallBlind:
+			problem = fmt.Sprintf("big blind %d is less than the small blind %d", level.BigBlind, level.SmallBlind)
+		case level.Ante < 0:
+			problem = fmt.Sprintf("ante can't be negative, got %d", level.Ante)
+		case level.Duration < 0, level.Duration == 0 && i < len(s.Levels)-1:
+			problem = fmt.Sprintf("duration must be more than 0, got %v", time.Duration(level.Duration))
+		case i > 0 && level.BigBlind < s.Levels[i-1].BigBlind:
+			problem = fmt.Sprintf("big blind %d is less than the level before's %d", level.BigBlind, s.Levels[i-1].BigBlind)
+		}
+		if problem != "" {
+			return fmt.Errorf("%w%s: level %d %s", ErrBadBlindStructure, named, i+1, problem)
+		}
+	}
+	return nil
+}
+
+// ParseBlindStructure reads a blind structure in JSONFormat or YAMLFormat
+// and checks it is valid. Unknown fields are an error, to catch typos.
+func ParseBlindStructure(r io.Reader, format string) (BlindStructure, error) {
+	structure, err := decodeBlindStructure(r, format)
+	if err != nil {
+		return BlindStructure{}, err
+	}
+	return structure, structure.Validate()
+}
+
+func decodeBlindStructure(r io.Reader, format string) (BlindStructure, error) {
+	var structure BlindStructure
+	var err error
+
+	switch format {
+	case JSONFormat:
+		decoder := json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&structure)
+	case YAMLFormat, "yml":
+		decoder := yaml.NewDecoder(r)
+		decoder.KnownFields(true)
+		err = decoder.Decode(&structure)
+	default:
+		return BlindStructure{}, fmt.Errorf("unknown blind structure format %q, want %q or %q", format, JSONFormat, YAMLFormat)
+	}
+
+	if err != nil {
+		return BlindStructure{}, fmt.Errorf("%w: problem parsing it, %v", ErrBadBlindStructure, err)
+	}
+	return structure, nil
+}
+
+// LoadBlindStructure reads the blind structure in a .json, .yaml or .yml
+// file. It is named after the file unless it has a name of its own.
+func LoadBlindStructure(path string) (BlindStructure, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return BlindStructure{}, fmt.Errorf("problem opening blind structure %s, %v", path, err)
+	}
+	defer file.Close()
+
+	ext := filepath.Ext(path)
+	structure, err := decodeBlindStructure(file, strings.ToLower(strings.TrimPrefix(ext, ".")))
+	if err == nil {
+		if structure.Name == "" {
+			structure.Name = strings.TrimSuffix(filepath.Base(path), ext)
+		}
+		err = structure.Validate()
+	}
+	if err != nil {
+		return BlindStructure{}, fmt.Errorf("problem loading %s, %w", path, err)
+	}
+	return structure, nil
+}
+
+// BlindStructures are the blind structures a game can be played with, by
+// name.
+type BlindStructures map[string]BlindStructure
+
+// LoadBlindStructures loads the blind structure in a file, or every one in
+// a directory.
+func LoadBlindStructures(path string) (BlindStructures, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("problem opening blind structures %s, %v", path, err)
+	}
+
+	paths := []string{path}
+	if info.IsDir() {
+		paths = nil
+		for _, pattern := range []string{"*.json", "*.yaml", "*.yml"} {
+			matches, _ := filepath.Glob(filepath.Join(path, pattern))
+			paths = append(paths, matches...)
+		}
+	}
+
+	structures := BlindStructures{}
+	for _, p := range paths {
+		structure, err := LoadBlindStructure(p)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := structures[structure.Name]; ok {
+			return nil, fmt.Errorf("problem loading %s, %w: %s is named twice", p, ErrBadBlindStructure, structure.Name)
+		}
+		structures[structure.Name] = structure
+	}
+	return structures, nil
+}
+
+// Names lists the structures in name order.
+func (b BlindStructures) Names() []string {
+	names := []string{}
+	for name := range b {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Find returns the structure with the given name. No name gives the zero
+// BlindStructure, which games take to mean the DefaultBlindStructure.
+func (b BlindStructures) Find(name string) (BlindStructure, error) {
+	if name == "" {
+		return BlindStructure{}, nil
+	}
+	structure, ok := b[name]
+	if !ok {
+		return BlindStructure{}, fmt.Errorf("%w: %s", ErrUnknownBlindStructure, name)
+	}
+	return structure, nil
+}
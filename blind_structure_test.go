@@ -0,0 +1,167 @@
+package poker_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	poker "github.com/tsugoshi/learn-go-application"
+)
+
+func TestParseBlindStructure(t *testing.T) {
+	want := poker.BlindStructure{Name: "turbo", Levels: []poker.BlindLevel{
+		{SmallBlind: 25, BigBlind: 50, Duration: poker.Duration(3 * time.Minute)},
+		{SmallBlind: 50, BigBlind: 100, Ante: 10, Duration: poker.Duration(90 * time.Second)},
+		{SmallBlind: 100, BigBlind: 200, Ante: 25},
+	}}
+
+	t.Run("it reads JSON", func(t *testing.T) {
+		got, err := poker.ParseBlindStructure(strings.NewReader(`{"name": "turbo", "levels": [
+			{"smallBlind": 25, "bigBlind": 50, "duration": "3m"},
+			{"smallBlind": 50, "bigBlind": 100, "ante": 10, "duration": "1m30s"},
+			{"smallBlind": 100, "bigBlind": 200, "ante": 25, "duration": "0s"}
+		]}`), poker.JSONFormat)
+
+		assertNoError(t, err)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, wanted %v", got, want)
+		}
+	})
+
+	t.Run("it reads YAML", func(t *testing.T) {
+		got, err := poker.ParseBlindStructure(strings.NewReader(`name: turbo
+levels:
+  - {smallBlind: 25, bigBlind: 50, duration: 3m}
+  - {smallBlind: 50, bigBlind: 100, ante: 10, duration: 1m30s}
+  - {smallBlind: 100, bigBlind: 200, ante: 25, duration: 0s}
+`), poker.YAMLFormat)
+
+		assertNoError(t, err)
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, wanted %v", got, want)
+		}
+	})
+
+	t.Run("it says what is wrong with a bad structure", func(t *testing.T) {
+		cases := []struct {
+			name      string
+			structure string
+			wantError string
+		}{
+			{"no levels", `{"name": "empty", "levels": []}`, "empty: it has no levels"},
+			{"no small blind", `{"levels": [{"bigBlind": 50, "duration": "3m"}]}`, "level 1 small blind must be more than 0"},
+			{"big blind too small", `{"levels": [{"smallBlind": 50, "bigBlind": 25, "duration": "3m"}]}`, "level 1 big blind 25 is less than the small blind 50"},
+			{"negative ante", `{"levels": [{"smallBlind": 25, "bigBlind": 50, "ante": -5}]}`, "level 1 ante can't be negative"},
+			{"level without a duration", `{"levels": [{"smallBlind": 25, "bigBlind": 50}, {"smallBlind": 50, "bigBlind": 100}]}`, "level 1 duration must be more than 0"},
+			{"blinds going down", `{"levels": [{"smallBlind": 50, "bigBlind": 100, "duration": "3m"}, {"smallBlind": 25, "bigBlind": 50}]}`, "level 2 big blind 50 is less than the level before's 100"},
+			{"bad duration", `{"levels": [{"smallBlind": 25, "bigBlind": 50, "duration": "soon"}]}`, `duration must be like "15m", got "soon"`},
+			{"breaks too often", `{"levels": [{"smallBlind": 25, "bigBlind": 50}], "breaks": {"every": 0, "duration": "10m"}}`, "breaks must come every 1 or more levels"},
+			{"breaks too short", `{"levels": [{"smallBlind": 25, "bigBlind": 50}], "breaks": {"every": 4}}`, "breaks must last more than 0"},
+			{"negative warning", `{"levels": [{"smallBlind": 25, "bigBlind": 50}], "warning": "-1m"}`, "warning can't be negative"},
+			{"unknown field", `{"levels": [{"smallBlind": 25, "big": 50}]}`, `unknown field "big"`},
+		}
+
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				_, err := poker.ParseBlindStructure(strings.NewReader(c.structure), poker.JSONFormat)
+				if !errors.Is(err, poker.ErrBadBlindStructure) {
+					t.Fatalf("got error %v, wanted %v", err, poker.ErrBadBlindStructure)
+				}
+				if !strings.Contains(err.Error(), c.wantError) {
+					t.Errorf("got error %q, wanted it to say %q", err, c.wantError)
+				}
+			})
+		}
+	})
+
+	t.Run("it reads breaks and warnings", func(t *testing.T) {
+		got, err := poker.ParseBlindStructure(strings.NewReader(`name: with breaks
+warning: 1m
+breaks:
+  every: 4
+  duration: 10m
+  notices: [colour up the 25 chips]
+levels:
+  - {smallBlind: 25, bigBlind: 50}
+`), poker.YAMLFormat)
+		assertNoError(t, err)
+
+		wantBreaks := &poker.Breaks{Every: 4, Duration: poker.Duration(10 * time.Minute), Notices: []string{"colour up the 25 chips"}}
+		if !reflect.DeepEqual(got.Breaks, wantBreaks) || got.Warning != poker.Duration(time.Minute) {
+			t.Errorf("got breaks %+v and warning %v, wanted %+v and 1m", got.Breaks, got.Warning, wantBreaks)
+		}
+	})
+
+	t.Run("it rejects unknown formats", func(t *testing.T) {
+		_, err := poker.ParseBlindStructure(strings.NewReader(""), "toml")
+		if err == nil {
+			t.Error("expected an error for an unknown format")
+		}
+	})
+}
+
+func TestLoadBlindStructures(t *testing.T) {
+	dir := t.TempDir()
+	writeBlindFile(t, dir, "turbo.yaml", "levels:\n  - {smallBlind: 25, bigBlind: 50}\n")
+	writeBlindFile(t, dir, "deep.json", `{"name": "deep stack", "levels": [{"smallBlind": 10, "bigBlind": 20}]}`)
+	writeBlindFile(t, dir, "notes.txt", "not a blind structure")
+
+	t.Run("it loads every structure in a directory, named after its file unless it has a name", func(t *testing.T) {
+		blinds, err := poker.LoadBlindStructures(dir)
+		assertNoError(t, err)
+
+		if got, want := blinds.Names(), []string{"deep stack", "turbo"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, wanted %v", got, want)
+		}
+
+		turbo, err := blinds.Find("turbo")
+		assertNoError(t, err)
+		if turbo.Levels[0].BigBlind != 50 {
+			t.Errorf("got %v, wanted a big blind of 50", turbo.Levels[0])
+		}
+	})
+
+	t.Run("it loads a single file", func(t *testing.T) {
+		blinds, err := poker.LoadBlindStructures(filepath.Join(dir, "turbo.yaml"))
+		assertNoError(t, err)
+
+		if got, want := blinds.Names(), []string{"turbo"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, wanted %v", got, want)
+		}
+	})
+
+	t.Run("it says which file is bad", func(t *testing.T) {
+		bad := t.TempDir()
+		writeBlindFile(t, bad, "broken.json", `{"levels": []}`)
+
+		_, err := poker.LoadBlindStructures(bad)
+		if !errors.Is(err, poker.ErrBadBlindStructure) || !strings.Contains(err.Error(), "broken.json") {
+			t.Errorf("got error %v, wanted it to name broken.json", err)
+		}
+	})
+
+	t.Run("it finds the default for no name and an error for an unknown one", func(t *testing.T) {
+		blinds := poker.BlindStructures{}
+
+		got, err := blinds.Find("")
+		assertNoError(t, err)
+		if len(got.Levels) != 0 {
+			t.Errorf("got %v, wanted the zero structure", got)
+		}
+
+		if _, err := blinds.Find("glacial"); !errors.Is(err, poker.ErrUnknownBlindStructure) {
+			t.Errorf("got error %v, wanted %v", err, poker.ErrUnknownBlindStructure)
+		}
+	})
+}
+
+func writeBlindFile(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -2,16 +2,20 @@ package poker
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"regexp"
 	"strconv"
 	"strings"
 )
 
 type CLI struct {
-	game Game
-	in   *bufio.Scanner
-	out  io.Writer
+	game   Game
+	in     *bufio.Scanner
+	out    io.Writer
+	blinds BlindStructures
 }
 
 func NewCLI(game Game, in io.Reader, out io.Writer) *CLI {
@@ -22,29 +26,163 @@ func NewCLI(game Game, in io.Reader, out io.Writer) *CLI {
 	}
 }
 
-const PlayerGreeting = "Please enter the number of players: "
-const BadStartInput = "Expected number of players"
+const PlayerGreeting = "Please enter the number of players, or their names: "
+const BadStartInput = "Expected number of players or at least two names"
+const BadStart = "Could not start the game: "
+const RetryStart = "Please enter the players again: "
+const BadFinish = "Could not record the winner: "
+const RetryFinish = "Please enter the result again: "
+const BlindsPrompt = "Please choose the blinds, or press enter for the default: "
+const BadBlinds = "Could not use those blinds: "
+const BadClockCommand = "Could not adjust the clock: "
 
+// UseBlindStructures offers the blind structures to choose from once the
+// players are entered.
+func (cli *CLI) UseBlindStructures(blinds BlindStructures) {
+	cli.blinds = blinds
+}
+
+// PlayPoker starts a game with the players typed in and records its result.
+// If a player isn't on the roster it says who they might have meant and asks
+// for the players again.
 func (cli *CLI) PlayPoker() {
 	fmt.Fprint(cli.out, PlayerGreeting)
 
 	numberOfPlayersInput := cli.readLine()
-	numberOfPlayers, err := strconv.Atoi(numberOfPlayersInput)
+	numberOfPlayers, players, err := extractPlayers(numberOfPlayersInput)
 
 	if err != nil {
 		fmt.Fprint(cli.out, BadStartInput)
 		return
 	}
 
-	cli.game.Start(numberOfPlayers, cli.out)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	blinds := cli.chooseBlinds()
+
+	for {
+		game, err := cli.game.Start(ctx, numberOfPlayers, blinds, cli.out, players...)
+		if err == nil {
+			cli.finishGame(game)
+			return
+		}
+
+		fmt.Fprint(cli.out, BadStart, err)
+		if !errors.Is(err, ErrNotOnRoster) {
+			return
+		}
+
+		fmt.Fprint(cli.out, "\n", RetryStart)
+		if numberOfPlayers, players, err = extractPlayers(cli.readLine()); err != nil {
+			fmt.Fprint(cli.out, BadStartInput)
+			return
+		}
+	}
+}
+
+// chooseBlinds asks which blind structure to play with until it is given
+// one it knows, if there are any to choose from. Entering nothing chooses
+// the default.
+func (cli *CLI) chooseBlinds() BlindStructure {
+	if len(cli.blinds) == 0 {
+		return BlindStructure{}
+	}
 
-	winnerInput := cli.readLine()
-	winner := extractWinner(winnerInput)
-	cli.game.Finish(winner)
+	fmt.Fprintf(cli.out, "Blinds: %s\n", strings.Join(cli.blinds.Names(), ", "))
+	for {
+		fmt.Fprint(cli.out, BlindsPrompt)
+		if !cli.in.Scan() {
+			return BlindStructure{}
+		}
+		blinds, err := cli.blinds.Find(strings.TrimSpace(cli.in.Text()))
+		if err == nil {
+			return blinds
+		}
+		fmt.Fprint(cli.out, BadBlinds, err, "\n")
+	}
 }
 
-func extractWinner(line string) string {
-	return strings.Replace(line, " wins", "", 1)
+// finishGame records the result typed in, adjusting the clock as it is told
+// to until then. If the result names someone who isn't on the roster it says
+// who they might have meant and asks again.
+func (cli *CLI) finishGame(game Tournament) {
+	line, _ := cli.readResult(game)
+	for {
+		winner, runnersUp := extractFinishingOrder(line)
+		err := game.Finish(winner, runnersUp...)
+		if err == nil {
+			return
+		}
+
+		fmt.Fprint(cli.out, BadFinish, err)
+		if !errors.Is(err, ErrNotOnRoster) {
+			return
+		}
+
+		fmt.Fprint(cli.out, "\n", RetryFinish)
+		var ok bool
+		if line, ok = cli.readResult(game); !ok {
+			return
+		}
+	}
+}
+
+// readResult reads lines until one isn't a clock command, running the
+// commands and printing where the clock is up to after each. It reports
+// false if the input ends first.
+func (cli *CLI) readResult(clock TournamentClock) (string, bool) {
+	for cli.in.Scan() {
+		line := cli.in.Text()
+		isCommand, err := runClockCommand(clock, line)
+		switch {
+		case !isCommand:
+			return line, true
+		case err != nil:
+			fmt.Fprint(cli.out, BadClockCommand, err, "\n")
+		default:
+			fmt.Fprintln(cli.out, clock.Status())
+		}
+	}
+	return "", false
+}
+
+// extractPlayers reads either a number of players or a comma separated list
+// of at least two names.
+func extractPlayers(line string) (int, []string, error) {
+	if numberOfPlayers, err := strconv.Atoi(strings.TrimSpace(line)); err == nil {
+		return numberOfPlayers, nil, nil
+	}
+
+	var players []string
+	for _, name := range strings.Split(line, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			players = append(players, name)
+		}
+	}
+
+	if len(players) < 2 {
+		return 0, nil, fmt.Errorf("expected a number or at least two names, got %q", line)
+	}
+	return len(players), players, nil
+}
+
+// winsSuffix is the " wins" after a winner's name, in any case.
+var winsSuffix = regexp.MustCompile(`(?i)\s+wins$`)
+
+// extractFinishingOrder reads either "Ruth wins" or a finishing order such
+// as "Ruth, Cleo, Chris", winner first.
+func extractFinishingOrder(line string) (winner string, runnersUp []string) {
+	for i, name := range strings.Split(line, ",") {
+		name = strings.TrimSpace(name)
+		if i == 0 {
+			winner = winsSuffix.ReplaceAllString(name, "")
+			continue
+		}
+		if name != "" {
+			runnersUp = append(runnersUp, name)
+		}
+	}
+	return winner, runnersUp
 }
 
 func (cli *CLI) readLine() string {
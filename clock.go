@@ -0,0 +1,322 @@
+package poker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrClockPaused     = errors.New("the clock is already paused")
+	ErrClockRunning    = errors.New("the clock is already running")
+	ErrNoMoreLevels    = errors.New("there are no more levels")
+	ErrFirstLevel      = errors.New("the clock is already at the first level")
+	ErrBadClockCommand = errors.New("bad clock command")
+)
+
+// TournamentClock controls how the blinds of a game go up.
+type TournamentClock interface {
+	Pause() error
+	Resume() error
+	// Next skips to the start of the next level.
+	Next() error
+	// Back goes back to the start of the level before.
+	Back() error
+	// AddTime makes the current level last longer.
+	AddTime(d time.Duration) error
+	Status() ClockStatus
+}
+
+// ClockStatus is where a TournamentClock is up to.
+type ClockStatus struct {
+	// Level counts from 1.
+	Level  int
+	Levels int
+	Blinds BlindLevel
+	// Remaining is how long the level has left, or 0 for the last level,
+	// which lasts until the game finishes.
+	Remaining time.Duration
+	Paused    bool
+	// OnBreak is whether play has stopped for a break before the Level.
+	OnBreak bool
+}
+
+func (s ClockStatus) String() string {
+	status := fmt.Sprintf("level %d of %d, blinds %v", s.Level, s.Levels, s.Blinds)
+	if s.OnBreak {
+		status = fmt.Sprintf("break before level %d of %d, blinds %v", s.Level, s.Levels, s.Blinds)
+	}
+	if s.Level < s.Levels {
+		status += fmt.Sprintf(", %v left", s.Remaining.Round(time.Second))
+	}
+	if s.Paused {
+		status += ", paused"
+	}
+	return status
+}
+
+// BlindClock is the TournamentClock games are played with. It schedules an
+// alert with its BlindAlerter for each level and break to come, and each
+// warning before them, scheduling them again whenever it is adjusted.
+type BlindClock struct {
+	clock   Clock
+	alerter BlindAlerter
+	to      io.Writer
+	levels  []BlindLevel
+	periods []period
+	warning time.Duration
+	game    context.Context
+
+	mu     sync.Mutex
+	period int
+	// remaining is how long the period had left at mark.
+	remaining time.Duration
+	mark      time.Time
+	paused    bool
+	// cancel cancels the alerts scheduled so far.
+	cancel context.CancelFunc
+	// unannounce cancels the alert for the last period announced, in case it
+	// hasn't been sent before another is.
+	unannounce context.CancelFunc
+}
+
+// period is a stretch of a game: a level of blinds, or a break before one.
+type period struct {
+	// level is the level played, or played after the break.
+	level   int
+	onBreak bool
+	// length is 0 for the last level, which lasts until the game finishes.
+	length time.Duration
+	notice string
+}
+
+// periodsOf lays out the levels of blinds with their breaks in between.
+func periodsOf(blinds BlindStructure) []period {
+	var periods []period
+	breaks := blinds.Breaks
+	for i, level := range blinds.Levels {
+		if breaks != nil && i > 0 && i%breaks.Every == 0 {
+			notice := ""
+			if n := i/breaks.Every - 1; n < len(breaks.Notices) {
+				notice = breaks.Notices[n]
+			}
+			periods = append(periods, period{level: i, onBreak: true, length: time.Duration(breaks.Duration), notice: notice})
+		}
+		periods = append(periods, period{level: i, length: time.Duration(level.Duration)})
+	}
+	periods[len(periods)-1].length = 0
+	return periods
+}
+
+// NewBlindClock starts a clock at the first level of blinds, which must have
+// levels, alerting to until ctx is done. It keeps time with clock.
+func NewBlindClock(ctx context.Context, clock Clock, alerter BlindAlerter, blinds BlindStructure, to io.Writer) *BlindClock {
+	c := &BlindClock{
+		clock:   clock,
+		alerter: alerter,
+		to:      to,
+		levels:  blinds.Levels,
+		periods: periodsOf(blinds),
+		warning: time.Duration(blinds.Warning),
+		game:    ctx,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.startPeriod(0)
+	return c
+}
+
+func (c *BlindClock) Pause() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.paused {
+		return ErrClockPaused
+	}
+	c.catchUp()
+	c.paused = true
+	c.schedule(false)
+	return nil
+}
+
+func (c *BlindClock) Resume() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.paused {
+		return ErrClockRunning
+	}
+	c.paused = false
+	c.mark = c.clock.Now()
+	c.schedule(false)
+	return nil
+}
+
+// Next skips to the start of the next level, or the break before it.
+func (c *BlindClock) Next() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.catchUp()
+	if c.period == len(c.periods)-1 {
+		return ErrNoMoreLevels
+	}
+	c.startPeriod(c.period + 1)
+	return nil
+}
+
+// Back goes back to the start of the level before, or the break before
+// this level.
+func (c *BlindClock) Back() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.catchUp()
+	if c.period == 0 {
+		return ErrFirstLevel
+	}
+	c.startPeriod(c.period - 1)
+	return nil
+}
+
+func (c *BlindClock) AddTime(d time.Duration) error {
+	if d <= 0 {
+		return fmt.Errorf("%w: time to add must be more than 0, got %v", ErrBadClockCommand, d)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.catchUp()
+	if c.period == len(c.periods)-1 {
+		return fmt.Errorf("%w: the last level lasts until the game finishes", ErrBadClockCommand)
+	}
+	c.remaining += d
+	c.schedule(false)
+	return nil
+}
+
+// Status is where the clock is up to. On a break it gives the level played
+// after it.
+func (c *BlindClock) Status() ClockStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.catchUp()
+	current := c.periods[c.period]
+	status := ClockStatus{
+		Level:     current.level + 1,
+		Levels:    len(c.levels),
+		Blinds:    c.levels[current.level],
+		Remaining: c.remaining,
+		Paused:    c.paused,
+		OnBreak:   current.onBreak,
+	}
+	if c.period == len(c.periods)-1 {
+		status.Remaining = 0
+	}
+	return status
+}
+
+// startPeriod starts the period from the beginning, alerting that it has.
+func (c *BlindClock) startPeriod(period int) {
+	c.period = period
+	c.remaining = c.periods[period].length
+	c.mark = c.clock.Now()
+	c.schedule(true)
+}
+
+// catchUp moves the clock on to whichever period it is in now, as the alerts
+// for the periods in between will have been sent.
+func (c *BlindClock) catchUp() {
+	if c.paused {
+		return
+	}
+
+	now := c.clock.Now()
+	elapsed := now.Sub(c.mark)
+	for elapsed >= c.remaining && c.period < len(c.periods)-1 {
+		elapsed -= c.remaining
+		c.period++
+		c.remaining = c.periods[c.period].length
+	}
+	c.remaining -= elapsed
+	c.mark = now
+}
+
+// schedule cancels the alerts scheduled so far and schedules one for each
+// period to come, and a warning before each level, unless the clock is
+// paused. With announce the current period is alerted straight away, which
+// only announcing another period or the game finishing cancels.
+func (c *BlindClock) schedule(announce bool) {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	var ctx context.Context
+	ctx, c.cancel = context.WithCancel(c.game)
+
+	if announce {
+		if c.unannounce != nil {
+			c.unannounce()
+		}
+		var announcement context.Context
+		announcement, c.unannounce = context.WithCancel(c.game)
+		c.alerter.ScheduleAlertAt(announcement, 0, c.alertFor(c.periods[c.period]), c.to)
+	}
+	if c.paused {
+		return
+	}
+
+	at := c.remaining
+	for _, next := range c.periods[c.period+1:] {
+		if !next.onBreak && c.warning > 0 && at > c.warning {
+			warning := Alert{Kind: BlindsWarning, Level: c.levels[next.level], In: c.warning}
+			c.alerter.ScheduleAlertAt(ctx, at-c.warning, warning, c.to)
+		}
+		c.alerter.ScheduleAlertAt(ctx, at, c.alertFor(next), c.to)
+		at += next.length
+	}
+}
+
+// alertFor is the alert that the period has started.
+func (c *BlindClock) alertFor(p period) Alert {
+	if p.onBreak {
+		return Alert{Kind: BreakStart, Level: c.levels[p.level], Break: p.length, Notice: p.notice}
+	}
+	return Alert{Kind: BlindsUp, Level: c.levels[p.level]}
+}
+
+// runClockCommand runs a command typed to adjust the clock: pause, resume,
+// next, back or add followed by a duration like 5m. It reports whether the
+// line was a clock command at all. A player named like a command wins with
+// "{Name} wins" or a finishing order, which are never commands.
+func runClockCommand(clock TournamentClock, line string) (bool, error) {
+	fields := strings.Fields(strings.ToLower(line))
+	if len(fields) == 0 {
+		return false, nil
+	}
+
+	switch command, args := fields[0], fields[1:]; {
+	case command == "pause" && len(args) == 0:
+		return true, clock.Pause()
+	case command == "resume" && len(args) == 0:
+		return true, clock.Resume()
+	case command == "next" && len(args) == 0:
+		return true, clock.Next()
+	case command == "back" && len(args) == 0:
+		return true, clock.Back()
+	case command == "add" && len(args) == 1:
+		d, err := time.ParseDuration(args[0])
+		if err != nil {
+			return true, fmt.Errorf("%w: want a duration like 5m, got %q", ErrBadClockCommand, args[0])
+		}
+		return true, clock.AddTime(d)
+	default:
+		return false, nil
+	}
+}
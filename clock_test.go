@@ -0,0 +1,277 @@
+package poker_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	poker "github.com/tsugoshi/learn-go-application"
+)
+
+var threeLevels = poker.BlindStructure{Name: "three", Levels: []poker.BlindLevel{
+	{SmallBlind: 100, BigBlind: 200, Duration: poker.Duration(10 * time.Minute)},
+	{SmallBlind: 200, BigBlind: 400, Duration: poker.Duration(10 * time.Minute)},
+	{SmallBlind: 400, BigBlind: 800},
+}}
+
+func TestBlindClock(t *testing.T) {
+	var now *poker.FakeClock
+	startClock := func() (*poker.BlindClock, *poker.SpyBlindAlerter) {
+		alerter := &poker.SpyBlindAlerter{}
+		now = poker.NewFakeClock(time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC))
+		return poker.NewBlindClock(context.Background(), now, alerter, threeLevels, ioutil.Discard), alerter
+	}
+
+	t.Run("it alerts the first level and schedules the rest", func(t *testing.T) {
+		clock, alerter := startClock()
+
+		assertAnnounced(t, alerter, 100)
+		assertPendingAlerts(t, alerter, []poker.ScheduledAlert{
+			{At: 10 * time.Minute, Amount: 200},
+			{At: 20 * time.Minute, Amount: 400},
+		})
+		assertClockStatus(t, clock, 1, false)
+	})
+
+	t.Run("it moves on a level as each one's time passes", func(t *testing.T) {
+		clock, _ := startClock()
+
+		now.Advance(9 * time.Minute)
+		assertClockStatus(t, clock, 1, false)
+		assertRemaining(t, clock, time.Minute)
+
+		now.Advance(2 * time.Minute)
+		assertClockStatus(t, clock, 2, false)
+		assertRemaining(t, clock, 9*time.Minute)
+
+		now.Advance(time.Hour)
+		assertClockStatus(t, clock, 3, false)
+		assertRemaining(t, clock, 0)
+	})
+
+	t.Run("pausing cancels the alerts and resuming schedules them again", func(t *testing.T) {
+		clock, alerter := startClock()
+
+		now.Advance(4 * time.Minute)
+		assertNoError(t, clock.Pause())
+		assertPendingAlerts(t, alerter, nil)
+		assertClockStatus(t, clock, 1, true)
+
+		now.Advance(time.Hour)
+		assertClockStatus(t, clock, 1, true)
+		assertRemaining(t, clock, 6*time.Minute)
+
+		assertNoError(t, clock.Resume())
+		assertPendingAlerts(t, alerter, []poker.ScheduledAlert{
+			{At: 6 * time.Minute, Amount: 200},
+			{At: 16 * time.Minute, Amount: 400},
+		})
+		assertClockStatus(t, clock, 1, false)
+	})
+
+	t.Run("it can't be paused or resumed twice", func(t *testing.T) {
+		clock, _ := startClock()
+
+		assertClockError(t, clock.Resume(), poker.ErrClockRunning)
+		assertNoError(t, clock.Pause())
+		assertClockError(t, clock.Pause(), poker.ErrClockPaused)
+	})
+
+	t.Run("next skips to the start of the next level", func(t *testing.T) {
+		clock, alerter := startClock()
+
+		now.Advance(3 * time.Minute)
+		assertNoError(t, clock.Next())
+		assertAnnounced(t, alerter, 200)
+		assertPendingAlerts(t, alerter, []poker.ScheduledAlert{
+			{At: 10 * time.Minute, Amount: 400},
+		})
+		assertClockStatus(t, clock, 2, false)
+		assertRemaining(t, clock, 10*time.Minute)
+
+		assertNoError(t, clock.Next())
+		assertClockError(t, clock.Next(), poker.ErrNoMoreLevels)
+		assertClockStatus(t, clock, 3, false)
+	})
+
+	t.Run("back goes back to the start of the level before", func(t *testing.T) {
+		clock, alerter := startClock()
+
+		assertClockError(t, clock.Back(), poker.ErrFirstLevel)
+		assertNoError(t, clock.Next())
+		assertNoError(t, clock.Back())
+
+		assertAnnounced(t, alerter, 100)
+		assertPendingAlerts(t, alerter, []poker.ScheduledAlert{
+			{At: 10 * time.Minute, Amount: 200},
+			{At: 20 * time.Minute, Amount: 400},
+		})
+		assertClockStatus(t, clock, 1, false)
+	})
+
+	t.Run("next while paused alerts the level but schedules nothing more", func(t *testing.T) {
+		clock, alerter := startClock()
+
+		assertNoError(t, clock.Pause())
+		assertNoError(t, clock.Next())
+
+		assertAnnounced(t, alerter, 200)
+		assertPendingAlerts(t, alerter, nil)
+		assertClockStatus(t, clock, 2, true)
+	})
+
+	t.Run("adding time puts the next levels back", func(t *testing.T) {
+		clock, alerter := startClock()
+
+		now.Advance(8 * time.Minute)
+		assertNoError(t, clock.AddTime(5*time.Minute))
+		assertPendingAlerts(t, alerter, []poker.ScheduledAlert{
+			{At: 7 * time.Minute, Amount: 200},
+			{At: 17 * time.Minute, Amount: 400},
+		})
+		assertRemaining(t, clock, 7*time.Minute)
+
+		assertClockError(t, clock.AddTime(-time.Minute), poker.ErrBadClockCommand)
+		assertNoError(t, clock.Next())
+		assertNoError(t, clock.Next())
+		assertClockError(t, clock.AddTime(time.Minute), poker.ErrBadClockCommand)
+	})
+
+	t.Run("it schedules breaks and warns before each level", func(t *testing.T) {
+		alerter := &poker.SpyBlindAlerter{}
+		now = poker.NewFakeClock(time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC))
+		blinds := threeLevels
+		blinds.Warning = poker.Duration(time.Minute)
+		blinds.Breaks = &poker.Breaks{Every: 2, Duration: poker.Duration(10 * time.Minute), Notices: []string{"colour up the 25 chips"}}
+		clock := poker.NewBlindClock(context.Background(), now, alerter, blinds, ioutil.Discard)
+
+		assertPendingAlerts(t, alerter, []poker.ScheduledAlert{
+			{At: 9 * time.Minute, Amount: 200, Kind: poker.BlindsWarning},
+			{At: 10 * time.Minute, Amount: 200},
+			{At: 20 * time.Minute, Amount: 400, Kind: poker.BreakStart},
+			{At: 29 * time.Minute, Amount: 400, Kind: poker.BlindsWarning},
+			{At: 30 * time.Minute, Amount: 400},
+		})
+
+		now.Advance(25 * time.Minute)
+		status := clock.Status()
+		if !status.OnBreak || status.Level != 3 || status.Remaining != 5*time.Minute {
+			t.Errorf("got %v, wanted a break before level 3 with 5m left", status)
+		}
+
+		assertNoError(t, clock.Back())
+		assertAnnounced(t, alerter, 200)
+		assertNoError(t, clock.Next())
+		assertNoError(t, clock.Next())
+		assertAnnounced(t, alerter, 400)
+		assertClockStatus(t, clock, 3, false)
+	})
+
+	t.Run("it doesn't warn of a level starting sooner than the warning", func(t *testing.T) {
+		alerter := &poker.SpyBlindAlerter{}
+		now = poker.NewFakeClock(time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC))
+		blinds := threeLevels
+		blinds.Warning = poker.Duration(time.Minute)
+		clock := poker.NewBlindClock(context.Background(), now, alerter, blinds, ioutil.Discard)
+
+		now.Advance(9*time.Minute + 30*time.Second)
+		assertNoError(t, clock.AddTime(time.Second))
+
+		assertPendingAlerts(t, alerter, []poker.ScheduledAlert{
+			{At: 31 * time.Second, Amount: 200},
+			{At: 9*time.Minute + 31*time.Second, Amount: 400, Kind: poker.BlindsWarning},
+			{At: 10*time.Minute + 31*time.Second, Amount: 400},
+		})
+	})
+
+	t.Run("it schedules nothing once the game is over", func(t *testing.T) {
+		alerter := &poker.SpyBlindAlerter{}
+		ctx, finish := context.WithCancel(context.Background())
+		clock := poker.NewBlindClock(ctx, poker.NewFakeClock(time.Now()), alerter, threeLevels, ioutil.Discard)
+		finish()
+
+		assertNoError(t, clock.Next())
+		if pending := alerter.Pending(); len(pending) > 0 {
+			t.Errorf("got alerts %v pending after the game finished", pending)
+		}
+	})
+}
+
+func TestClockStatus(t *testing.T) {
+	cases := []struct {
+		status poker.ClockStatus
+		want   string
+	}{
+		{poker.ClockStatus{Level: 1, Levels: 3, Blinds: threeLevels.Levels[0], Remaining: 9*time.Minute + 30*time.Second}, "level 1 of 3, blinds 100/200, 9m30s left"},
+		{poker.ClockStatus{Level: 2, Levels: 3, Blinds: threeLevels.Levels[1], Remaining: time.Minute, Paused: true}, "level 2 of 3, blinds 200/400, 1m0s left, paused"},
+		{poker.ClockStatus{Level: 3, Levels: 3, Blinds: threeLevels.Levels[2]}, "level 3 of 3, blinds 400/800"},
+		{poker.ClockStatus{Level: 3, Levels: 3, Blinds: threeLevels.Levels[2], Remaining: 5 * time.Minute, OnBreak: true}, "break before level 3 of 3, blinds 400/800"},
+	}
+
+	for _, c := range cases {
+		if got := c.status.String(); got != c.want {
+			t.Errorf("got %q, wanted %q", got, c.want)
+		}
+	}
+}
+
+// assertPendingAlerts checks the alerts still to come after the levels
+// announced straight away.
+func assertPendingAlerts(t testing.TB, alerter *poker.SpyBlindAlerter, want []poker.ScheduledAlert) {
+	t.Helper()
+
+	var got []poker.ScheduledAlert
+	for _, alert := range alerter.Pending() {
+		if alert.At > 0 {
+			got = append(got, alert)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d alerts pending %v, wanted %v", len(got), got, want)
+	}
+	for i := range want {
+		if got[i].Amount != want[i].Amount || got[i].At != want[i].At || got[i].Kind != want[i].Kind {
+			t.Errorf("got alert %v, wanted %v", got[i], want[i])
+		}
+	}
+}
+
+// assertAnnounced checks the latest level announced straight away.
+func assertAnnounced(t testing.TB, alerter *poker.SpyBlindAlerter, smallBlind int) {
+	t.Helper()
+
+	announced := 0
+	for _, alert := range alerter.Pending() {
+		if alert.At == 0 {
+			announced = alert.Amount
+		}
+	}
+	if announced != smallBlind {
+		t.Errorf("got %d announced, wanted %d", announced, smallBlind)
+	}
+}
+
+func assertClockStatus(t testing.TB, clock poker.TournamentClock, level int, paused bool) {
+	t.Helper()
+
+	status := clock.Status()
+	if status.Level != level || status.Paused != paused {
+		t.Errorf("got %v, wanted level %d paused %v", status, level, paused)
+	}
+}
+
+func assertRemaining(t testing.TB, clock poker.TournamentClock, want time.Duration) {
+	t.Helper()
+	if got := clock.Status().Remaining; got != want {
+		t.Errorf("got %v left, wanted %v", got, want)
+	}
+}
+
+func assertClockError(t testing.TB, got, want error) {
+	t.Helper()
+	if !errors.Is(got, want) {
+		t.Errorf("got error %v, wanted %v", got, want)
+	}
+}
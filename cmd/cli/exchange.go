@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	poker "github.com/tsugoshi/learn-go-application"
+)
+
+// exportCommand writes the league, or its games, to a file or stdout.
+func exportCommand(store poker.ExchangeStore, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	format := flags.String("format", poker.CSVFormat, "format to export in: csv or json")
+	games := flags.Bool("games", false, "export the games rather than the league when exporting CSV")
+	outFile := flags.String("o", "", "file to export to (defaults to stdout)")
+	flags.Parse(args)
+
+	out := io.Writer(os.Stdout)
+	if *outFile != "" {
+		file, err := os.Create(*outFile)
+		if err != nil {
+			return fmt.Errorf("problem creating %s, %v", *outFile, err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch {
+	case *format == poker.JSONFormat:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(poker.ExportLeague(store))
+	case *format == poker.CSVFormat && *games:
+		return poker.WriteGamesCSV(out, store.GetGames().Finished())
+	case *format == poker.CSVFormat:
+		return poker.WriteLeagueCSV(out, store.GetLeague())
+	default:
+		return fmt.Errorf("unknown export format %q, want %q or %q", *format, poker.CSVFormat, poker.JSONFormat)
+	}
+}
+
+// importCommand imports an exported file, or reports what importing it would
+// do with -dry-run.
+func importCommand(store poker.ExchangeStore, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", false, "report what would be imported and which players are already in the league, without importing anything")
+	format := flags.String("format", "", "format of the file: csv or json (defaults to the file's extension)")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected the file to import, like: import -dry-run league.csv")
+	}
+	path := flags.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("problem opening %s, %v", path, err)
+	}
+	defer file.Close()
+
+	export, err := poker.ReadLeagueExport(file, *format)
+	if err != nil {
+		return err
+	}
+
+	report, err := poker.ImportLeague(store, export, *dryRun)
+	printImportReport(os.Stdout, report)
+	return err
+}
+
+func printImportReport(out io.Writer, report poker.ImportReport) {
+	did := "Imported"
+	if report.DryRun {
+		did = "Would import"
+	}
+	if report.Games > 0 || report.Wins == 0 {
+		fmt.Fprintf(out, "%s %d games\n", did, report.Games)
+	}
+	if report.Wins > 0 {
+		fmt.Fprintf(out, "%s %d wins, without the games they were won in\n", did, report.Wins)
+	}
+	if len(report.Duplicates) > 0 {
+		fmt.Fprintf(out, "Skipping %d games already recorded: %s\n", len(report.Duplicates), strings.Join(report.Duplicates, ", "))
+	}
+	if len(report.Conflicts) > 0 {
+		fmt.Fprintln(out, "These players are already in the league, and the import adds to their records:")
+		for _, conflict := range report.Conflicts {
+			fmt.Fprintf(out, "  %s %s\n", conflict.Name, conflict.Reason)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	poker "github.com/tsugoshi/learn-go-application"
+)
+
+func TestExportThenImport(t *testing.T) {
+	store := poker.NewInMemoryPlayerStore()
+	if err := store.RecordGame(poker.GameResult{Players: []string{"Ruth", "Cleo"}, Winner: "Ruth"}); err != nil {
+		t.Fatalf("didn't expect error recording game, got %v", err)
+	}
+	if _, err := store.StartGame(poker.GameResult{Players: []string{"Ruth", "Chris"}}); err != nil {
+		t.Fatalf("didn't expect error starting game, got %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "games.csv")
+	if err := exportCommand(store, []string{"-games", "-format", poker.CSVFormat, "-o", path}); err != nil {
+		t.Fatalf("didn't expect error exporting games, got %v", err)
+	}
+
+	imported := poker.NewInMemoryPlayerStore()
+	if err := importCommand(imported, []string{path}); err != nil {
+		t.Fatalf("didn't expect error importing games, got %v", err)
+	}
+	if games := imported.GetGames(); len(games) != 1 || games[0].Winner != "Ruth" {
+		t.Errorf("got games %+v, wanted only Ruth's finished win", games)
+	}
+}
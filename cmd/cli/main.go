@@ -1,27 +1,77 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	poker "github.com/tsugoshi/learn-go-application"
 )
 
-const dbFileName = "game.db.json"
-
 func main() {
+	storeKind := flag.String("store", poker.FileStore, "player store to use: file, eventlog, bolt or memory")
+	dbFileName := flag.String("db", "", "file to keep the league in (defaults to one per store)")
+	roster := flag.Bool("roster", false, "only record games between players registered on the roster")
+	blindsPath := flag.String("blinds", "", "JSON or YAML blind structure file, or a directory of them, to choose from")
+	newSeason := flag.String("new-season", "", "start a season with this ID before playing, like "+poker.QuarterSeasonID(time.Now()))
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [export [-format csv|json] [-games] [-o file] | import [-dry-run] [-format csv|json] file]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *dbFileName == "" {
+		*dbFileName = poker.DefaultStorePath(*storeKind)
+	}
 
-	store, close, err := poker.FileSystemPlayerStoreFromFile(dbFileName)
+	store, close, err := poker.OpenPlayerStore(*storeKind, *dbFileName)
 
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer close()
 
+	if *roster {
+		store = poker.NewRosterPlayerStore(store)
+	}
+
+	switch command, args := flag.Arg(0), flag.Args(); command {
+	case "":
+	case "export", "import":
+		run := exportCommand
+		if command == "import" {
+			run = importCommand
+		}
+		if err := run(store, args[1:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	default:
+		flag.Usage()
+		log.Fatalf("unknown command %q, want export or import", command)
+	}
+
+	if *newSeason != "" {
+		if err := store.StartSeason(*newSeason); err != nil {
+			log.Fatalf("problem starting season %s, %v", *newSeason, err)
+		}
+		fmt.Printf("Season %s has started\n", *newSeason)
+	}
+
 	fmt.Println("Let's play poker")
 	fmt.Println("Type {Name} wins to record a win")
+	fmt.Println("or the finishing order, winner first, like Ruth, Cleo, Chris")
+	fmt.Println("Until then, type pause, resume, next, back or add 5m to adjust the clock")
 	game := poker.NewTexasHoldem(poker.BlindAlerterFunc(poker.Alerter), store)
 	cli := poker.NewCLI(game, os.Stdin, os.Stdout)
+	if *blindsPath != "" {
+		blinds, err := poker.LoadBlindStructures(*blindsPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		cli.UseBlindStructures(blinds)
+	}
 	cli.PlayPoker()
 }
@@ -1,21 +1,37 @@
 package main
 
 import (
+	"flag"
 	"log"
 	"net/http"
+	"os"
+	"sync"
 
 	poker "github.com/tsugoshi/learn-go-application"
 )
 
-const dbFileName = "game.db.json"
-
 func main() {
-	store, close, err := poker.FileSystemPlayerStoreFromFile(dbFileName)
+	storeKind := flag.String("store", poker.FileStore, "player store to use: file, eventlog, bolt or memory")
+	dbFileName := flag.String("db", "", "file to keep the league in (defaults to one per store)")
+	roster := flag.Bool("roster", false, "only record games between players registered on the roster")
+	blindsPath := flag.String("blinds", "", "JSON or YAML blind structure file, or a directory of them, to choose from")
+	leaguesDir := flag.String("leagues", "leagues", "directory to keep the other named leagues in")
+	flag.Parse()
+
+	if *dbFileName == "" {
+		*dbFileName = poker.DefaultStorePath(*storeKind)
+	}
+
+	store, close, err := poker.OpenPlayerStore(*storeKind, *dbFileName)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer close()
 
+	if *roster {
+		store = poker.NewRosterPlayerStore(store)
+	}
+
 	game := poker.NewTexasHoldem(poker.BlindAlerterFunc(poker.Alerter), store)
 
 	server, err := poker.NewPlayerServer(store, game)
@@ -24,6 +40,59 @@ func main() {
 		log.Fatalf("error creating PlayerServer %v", err)
 	}
 
+	if *blindsPath != "" {
+		blinds, err := poker.LoadBlindStructures(*blindsPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		server.UseBlindStructures(blinds)
+	}
+
+	var mu sync.Mutex
+	var closers []func()
+	defer func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, close := range closers {
+			close()
+		}
+	}()
+
+	openLeague := func(id string) (poker.PlayerStore, poker.Game, error) {
+		if *storeKind != poker.MemoryStore {
+			if err := os.MkdirAll(*leaguesDir, 0755); err != nil {
+				return nil, nil, err
+			}
+		}
+		store, close, err := poker.OpenPlayerStore(*storeKind, poker.LeagueStorePath(*leaguesDir, *storeKind, id))
+		if err != nil {
+			return nil, nil, err
+		}
+		mu.Lock()
+		closers = append(closers, close)
+		mu.Unlock()
+
+		if *roster {
+			store = poker.NewRosterPlayerStore(store)
+		}
+		return store, poker.NewTexasHoldem(poker.BlindAlerterFunc(poker.Alerter), store), nil
+	}
+
+	ids, err := poker.LeagueStoreIDs(*leaguesDir, *storeKind)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, id := range ids {
+		store, game, err := openLeague(id)
+		if err != nil {
+			log.Fatalf("problem opening league %s, %v", id, err)
+		}
+		if err := server.AddLeague(id, store, game); err != nil {
+			log.Fatal(err)
+		}
+	}
+	server.CreateLeaguesWith(openLeague)
+
 	if err := http.ListenAndServe(":5000", server); err != nil {
 		log.Fatalf("could not listen on port 5000 %v", err)
 	}
@@ -0,0 +1,233 @@
+package poker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Database is what a FileSystemPlayerStore saves to disk. The league is
+// worked out from the games when it is loaded.
+type Database struct {
+	Version  int              `json:"version"`
+	Metadata DatabaseMetadata `json:"metadata"`
+	Games    GameHistory      `json:"games"`
+	Seasons  []Season         `json:"seasons"`
+	// Roster is the players registered before playing a game.
+	Roster []string `json:"roster"`
+}
+
+type DatabaseMetadata struct {
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+	MigratedFrom int       `json:"migratedFrom,omitempty"`
+}
+
+// migrations[i] upgrades a database from version i+1 to version i+2. Add new
+// migrations to the end; DatabaseVersion follows automatically.
+var migrations = []func(data json.RawMessage) (json.RawMessage, error){
+	migrateBareLeague,
+	migratePlayersToGames,
+	addSeasons,
+	addRoster,
+	mergeDuplicatePlayers,
+}
+
+// DatabaseVersion is the version of Database this program writes.
+var DatabaseVersion = len(migrations) + 1
+
+func newDatabase() Database {
+	now := time.Now().UTC()
+	return Database{
+		Version:  DatabaseVersion,
+		Metadata: DatabaseMetadata{CreatedAt: now, UpdatedAt: now},
+		Games:    GameHistory{},
+		Seasons:  []Season{},
+		Roster:   []string{},
+	}
+}
+
+// readDatabase decodes a database of any version this program knows about,
+// upgrading it to DatabaseVersion. It reports whether any migrations ran.
+func readDatabase(rdr io.Reader) (Database, bool, error) {
+	var db Database
+
+	data, err := io.ReadAll(rdr)
+	if err != nil {
+		return db, false, fmt.Errorf("problem reading database, %v", err)
+	}
+
+	version, err := databaseVersion(data)
+	if err != nil {
+		return db, false, err
+	}
+
+	if version > DatabaseVersion {
+		return db, false, fmt.Errorf("database is version %d, newer than version %d this program understands, please upgrade", version, DatabaseVersion)
+	}
+
+	for v := version; v < DatabaseVersion; v++ {
+		data, err = migrations[v-1](data)
+		if err != nil {
+			return db, false, fmt.Errorf("problem migrating database from version %d to %d, %v", v, v+1, err)
+		}
+	}
+
+	if err := json.Unmarshal(data, &db); err != nil {
+		return db, false, fmt.Errorf("problem with database parsing %v", err)
+	}
+
+	migrated := version < DatabaseVersion
+	if migrated {
+		db.Metadata.MigratedFrom = version
+	}
+	return db, migrated, nil
+}
+
+// databaseVersion works out the version of data. Version 1 files were a bare
+// JSON array of players.
+func databaseVersion(data []byte) (int, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		return 1, nil
+	}
+
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return 0, fmt.Errorf("problem with database parsing %v", err)
+	}
+	if header.Version < 1 {
+		return 0, fmt.Errorf("database has no valid version, got %d", header.Version)
+	}
+	return header.Version, nil
+}
+
+func migrateBareLeague(data json.RawMessage) (json.RawMessage, error) {
+	now := time.Now().UTC()
+	return json.Marshal(map[string]interface{}{
+		"version": 2,
+		"metadata": map[string]interface{}{
+			"createdAt": now,
+			"updatedAt": now,
+		},
+		"players": data,
+	})
+}
+
+// migratePlayersToGames replaces each player's win count with one result per
+// win, since version 3 keeps games rather than totals.
+func migratePlayersToGames(data json.RawMessage) (json.RawMessage, error) {
+	var db map[string]json.RawMessage
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, err
+	}
+
+	var players League
+	if raw, ok := db["players"]; ok {
+		if err := json.Unmarshal(raw, &players); err != nil {
+			return nil, err
+		}
+	}
+
+	games, err := json.Marshal(legacyGames(players))
+	if err != nil {
+		return nil, err
+	}
+
+	delete(db, "players")
+	db["games"] = games
+	db["version"] = json.RawMessage("3")
+	return json.Marshal(db)
+}
+
+// legacyGames makes up a result for every win in a league saved before games
+// were recorded. They have no times, so they sort before any real game.
+func legacyGames(league League) GameHistory {
+	games := GameHistory{}
+	for _, player := range league {
+		for i := 1; i <= player.Wins; i++ {
+			games = append(games, GameResult{
+				ID:     fmt.Sprintf("legacy-%s-%d", player.Name, i),
+				Winner: player.Name,
+			})
+		}
+	}
+	return games
+}
+
+// addSeasons moves to version 4, which can hold seasons. Older versions had
+// none, so there is nothing to convert.
+func addSeasons(data json.RawMessage) (json.RawMessage, error) {
+	var db map[string]json.RawMessage
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, err
+	}
+
+	db["seasons"] = json.RawMessage("[]")
+	db["version"] = json.RawMessage("4")
+	return json.Marshal(db)
+}
+
+// addRoster moves to version 5, which can hold registered players. Everyone
+// in older files is already in the league, so the roster starts empty.
+func addRoster(data json.RawMessage) (json.RawMessage, error) {
+	var db map[string]json.RawMessage
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, err
+	}
+
+	db["roster"] = json.RawMessage("[]")
+	db["version"] = json.RawMessage("5")
+	return json.Marshal(db)
+}
+
+// mergeDuplicatePlayers moves to version 6, which treats names differing
+// only in case or spacing as the same player. Everyone is renamed to the first
+// name they were recorded under, merging players that were told apart before.
+func mergeDuplicatePlayers(data json.RawMessage) (json.RawMessage, error) {
+	var db map[string]json.RawMessage
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, err
+	}
+
+	games := GameHistory{}
+	roster := []string{}
+	if raw, ok := db["games"]; ok {
+		if err := json.Unmarshal(raw, &games); err != nil {
+			return nil, err
+		}
+	}
+	if raw, ok := db["roster"]; ok {
+		if err := json.Unmarshal(raw, &roster); err != nil {
+			return nil, err
+		}
+	}
+
+	book := nameBook{}
+	for _, game := range games {
+		for _, name := range game.Participants() {
+			book.add(name)
+		}
+	}
+	for _, name := range roster {
+		book.add(name)
+	}
+
+	for i := range games {
+		games[i] = games[i].withDisplayNames(book.display)
+	}
+	roster = displayAll(roster, book.display)
+
+	var err error
+	if db["games"], err = json.Marshal(games); err != nil {
+		return nil, err
+	}
+	if db["roster"], err = json.Marshal(roster); err != nil {
+		return nil, err
+	}
+	db["version"] = json.RawMessage("6")
+	return json.Marshal(db)
+}
@@ -0,0 +1,339 @@
+package poker
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Formats a league can be exported in and imported from.
+const (
+	CSVFormat  = "csv"
+	JSONFormat = "json"
+)
+
+// LeagueExport is a league as it is exported, with the games it was worked
+// out from when they are known.
+type LeagueExport struct {
+	League League      `json:"league"`
+	Games  GameHistory `json:"games,omitempty"`
+}
+
+// ExchangeStore is a store a league can be exported from and imported into.
+type ExchangeStore interface {
+	LeagueStore
+	GameStore
+}
+
+// ExportLeague returns everything in store that can be exported. Games that
+// haven't finished are left out, as they can't be imported without a result.
+func ExportLeague(store ExchangeStore) LeagueExport {
+	return LeagueExport{League: store.GetLeague(), Games: store.GetGames().Finished()}
+}
+
+var (
+	leagueCSVHeader = []string{"name", "wins", "games_played", "losses", "win_rate", "rating"}
+	gamesCSVHeader  = []string{"id", "started_at", "finished_at", "season", "winner", "finishing_order", "players", "number_of_players", "final_blind"}
+)
+
+// csvListSeparator separates the names in a list in a CSV cell.
+const csvListSeparator = ";"
+
+// WriteLeagueCSV writes the league as CSV, one player a row after a header.
+func WriteLeagueCSV(w io.Writer, league League) error {
+	out := csv.NewWriter(w)
+	out.Write(leagueCSVHeader)
+	for _, p := range league {
+		out.Write([]string{
+			p.Name,
+			strconv.Itoa(p.Wins),
+			strconv.Itoa(p.GamesPlayed),
+			strconv.Itoa(p.Losses),
+			strconv.FormatFloat(p.WinRate, 'f', -1, 64),
+			strconv.FormatFloat(p.Rating, 'f', -1, 64),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// WriteGamesCSV writes the games as CSV, one a row after a header. Lists of
+// names are separated by semicolons.
+func WriteGamesCSV(w io.Writer, games GameHistory) error {
+	out := csv.NewWriter(w)
+	out.Write(gamesCSVHeader)
+	for _, g := range games {
+		out.Write([]string{
+			g.ID,
+			formatCSVTime(g.StartedAt),
+			formatCSVTime(g.FinishedAt),
+			g.Season,
+			g.Winner,
+			strings.Join(g.FinishingOrder, csvListSeparator),
+			strings.Join(g.Players, csvListSeparator),
+			formatCSVInt(g.NumberOfPlayers),
+			formatCSVInt(g.FinalBlind),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// ReadLeagueExport reads an export in the given format. CSV holds either the
+// league or the games, going by its header.
+func ReadLeagueExport(r io.Reader, format string) (LeagueExport, error) {
+	switch format {
+	case JSONFormat:
+		var export LeagueExport
+		if err := json.NewDecoder(r).Decode(&export); err != nil {
+			return LeagueExport{}, fmt.Errorf("problem parsing export, %v", err)
+		}
+		return export, nil
+	case CSVFormat:
+		return readCSVExport(r)
+	default:
+		return LeagueExport{}, fmt.Errorf("unknown export format %q, want %q or %q", format, CSVFormat, JSONFormat)
+	}
+}
+
+func readCSVExport(r io.Reader) (LeagueExport, error) {
+	rows, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return LeagueExport{}, fmt.Errorf("problem parsing CSV, %v", err)
+	}
+	if len(rows) == 0 {
+		return LeagueExport{}, errors.New("problem parsing CSV, it is empty")
+	}
+
+	header, rows := rows[0], rows[1:]
+	switch strings.ToLower(strings.TrimSpace(header[0])) {
+	case leagueCSVHeader[0]:
+		league, err := readLeagueRows(columns(header), rows)
+		return LeagueExport{League: league}, err
+	case gamesCSVHeader[0]:
+		games, err := readGameRows(columns(header), rows)
+		return LeagueExport{League: games.League(), Games: games}, err
+	default:
+		return LeagueExport{}, fmt.Errorf("problem parsing CSV, want a league or games header, got %v", header)
+	}
+}
+
+// csvRow looks up a row's cells by the name of their column.
+type csvRow func(column string) string
+
+func columns(header []string) func(row []string) csvRow {
+	index := map[string]int{}
+	for i, column := range header {
+		index[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	return func(row []string) csvRow {
+		return func(column string) string {
+			if i, ok := index[column]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+	}
+}
+
+func readLeagueRows(cells func([]string) csvRow, rows [][]string) (League, error) {
+	league := League{}
+	for i, row := range rows {
+		cell := cells(row)
+		wins, err := parseCSVInt(cell("wins"))
+		if err != nil {
+			return nil, fmt.Errorf("problem parsing wins on row %d, %v", i+2, err)
+		}
+		played, err := parseCSVInt(cell("games_played"))
+		if err != nil {
+			return nil, fmt.Errorf("problem parsing games played on row %d, %v", i+2, err)
+		}
+		if cell("name") == "" {
+			return nil, fmt.Errorf("problem parsing row %d, %v", i+2, ErrNoPlayerName)
+		}
+		if played < wins {
+			played = wins
+		}
+		league = append(league, NewPlayer(cell("name"), wins, played))
+	}
+	return league, nil
+}
+
+func readGameRows(cells func([]string) csvRow, rows [][]string) (GameHistory, error) {
+	games := GameHistory{}
+	for i, row := range rows {
+		cell := cells(row)
+		game := GameResult{
+			ID:             cell("id"),
+			Season:         cell("season"),
+			Winner:         cell("winner"),
+			FinishingOrder: splitCSVList(cell("finishing_order")),
+			Players:        splitCSVList(cell("players")),
+		}
+
+		var err error
+		if game.StartedAt, err = parseCSVTime(cell("started_at")); err != nil {
+			return nil, fmt.Errorf("problem parsing started_at on row %d, %v", i+2, err)
+		}
+		if game.FinishedAt, err = parseCSVTime(cell("finished_at")); err != nil {
+			return nil, fmt.Errorf("problem parsing finished_at on row %d, %v", i+2, err)
+		}
+		if game.NumberOfPlayers, err = parseCSVInt(cell("number_of_players")); err != nil {
+			return nil, fmt.Errorf("problem parsing number_of_players on row %d, %v", i+2, err)
+		}
+		if game.FinalBlind, err = parseCSVInt(cell("final_blind")); err != nil {
+			return nil, fmt.Errorf("problem parsing final_blind on row %d, %v", i+2, err)
+		}
+		if game.Winner == "" {
+			return nil, fmt.Errorf("problem parsing row %d, game has no winner", i+2)
+		}
+		games = append(games, game)
+	}
+	return games, nil
+}
+
+func formatCSVTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339Nano)
+}
+
+func parseCSVTime(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+func formatCSVInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
+
+func parseCSVInt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+func splitCSVList(s string) []string {
+	var names []string
+	for _, name := range strings.Split(s, csvListSeparator) {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// ImportReport says what an import did, or would do in a dry run.
+type ImportReport struct {
+	DryRun bool `json:"dryRun"`
+	// Games is how many games were recorded.
+	Games int `json:"games"`
+	// Wins is how many wins were recorded on their own, for a league
+	// imported without its games.
+	Wins int `json:"wins"`
+	// Duplicates are the IDs of games already in the store, which are not
+	// recorded again.
+	Duplicates []string `json:"duplicates"`
+	// Conflicts are the players in the import who are already in the store.
+	Conflicts []ImportConflict `json:"conflicts"`
+}
+
+// ImportConflict is a player in an import who is already in the store, whose
+// record the import adds to.
+type ImportConflict struct {
+	Name     string `json:"name"`
+	Existing Player `json:"existing"`
+	Reason   string `json:"reason"`
+}
+
+// ImportLeague records the export's games in store all at once, skipping any
+// it already has. They keep the season they name, or stay in none. A league
+// exported without its games only brings players' wins, as their losses
+// can't be recorded without the games they were lost in. With dryRun nothing
+// is recorded, but the report says what would be.
+func ImportLeague(store ExchangeStore, export LeagueExport, dryRun bool) (ImportReport, error) {
+	report := ImportReport{DryRun: dryRun, Duplicates: []string{}, Conflicts: []ImportConflict{}}
+
+	known := map[string]bool{}
+	for _, game := range store.GetGames() {
+		known[game.ID] = true
+	}
+
+	var games GameHistory
+	for _, game := range export.Games {
+		if game.ID != "" && known[game.ID] {
+			report.Duplicates = append(report.Duplicates, game.ID)
+			continue
+		}
+		known[game.ID] = true
+		games = append(games, game)
+	}
+
+	var names []string
+	if len(export.Games) > 0 {
+		for _, game := range games {
+			names = append(names, game.Participants()...)
+		}
+	} else {
+		for _, player := range export.League {
+			names = append(names, player.Name)
+		}
+	}
+	report.Conflicts = importConflicts(store, names)
+
+	if len(export.Games) > 0 {
+		report.Games = len(games)
+	} else {
+		for _, player := range export.League {
+			for i := 0; i < player.Wins; i++ {
+				games = append(games, GameResult{Winner: player.Name})
+			}
+		}
+		report.Wins = len(games)
+	}
+
+	if dryRun || len(games) == 0 {
+		return report, nil
+	}
+	if err := store.ImportGames(games); err != nil {
+		report.Games, report.Wins = 0, 0
+		return report, fmt.Errorf("problem importing games, %w", err)
+	}
+	return report, nil
+}
+
+func importConflicts(store LeagueStore, names []string) []ImportConflict {
+	conflicts := []ImportConflict{}
+	seen := map[string]bool{}
+	for _, name := range names {
+		key := PlayerKey(name)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		existing, ok := store.GetPlayer(name)
+		renamed := cleanName(name) != existing.Name
+		if !ok || (existing.GamesPlayed == 0 && !renamed) {
+			continue
+		}
+		reason := fmt.Sprintf("already has %d wins from %d games", existing.Wins, existing.GamesPlayed)
+		if renamed {
+			reason = fmt.Sprintf("is already in the league as %s, with %d wins from %d games", existing.Name, existing.Wins, existing.GamesPlayed)
+		}
+		conflicts = append(conflicts, ImportConflict{Name: name, Existing: existing, Reason: reason})
+	}
+	return conflicts
+}
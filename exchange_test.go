@@ -0,0 +1,171 @@
+package poker_test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	poker "github.com/tsugoshi/learn-go-application"
+)
+
+func TestExportRoundTrip(t *testing.T) {
+	started := time.Date(2026, 7, 1, 19, 0, 0, 0, time.UTC)
+	games := poker.GameHistory{
+		{
+			ID:              "a1",
+			StartedAt:       started,
+			FinishedAt:      started.Add(time.Hour),
+			NumberOfPlayers: 3,
+			Players:         []string{"Ruth", "Cleo", "Chris"},
+			FinishingOrder:  []string{"Cleo", "Ruth", "Chris"},
+			Winner:          "Cleo",
+			FinalBlind:      400,
+			Season:          "2026-Q3",
+		},
+		{ID: "b2", FinishedAt: started.Add(2 * time.Hour), Winner: "Ruth"},
+	}
+
+	t.Run("games survive CSV", func(t *testing.T) {
+		var out bytes.Buffer
+		if err := poker.WriteGamesCSV(&out, games); err != nil {
+			t.Fatal(err)
+		}
+
+		export, err := poker.ReadLeagueExport(&out, poker.CSVFormat)
+		assertNoError(t, err)
+		if !reflect.DeepEqual(export.Games, games) {
+			t.Errorf("got games %+v, want %+v", export.Games, games)
+		}
+		poker.AssertLeague(t, export.League, games.League())
+	})
+
+	t.Run("the league survives CSV", func(t *testing.T) {
+		league := poker.League{poker.NewPlayer("Cleo", 3, 4), poker.NewPlayer("Chris, Jr", 0, 2)}
+		var out bytes.Buffer
+		if err := poker.WriteLeagueCSV(&out, league); err != nil {
+			t.Fatal(err)
+		}
+
+		export, err := poker.ReadLeagueExport(&out, poker.CSVFormat)
+		assertNoError(t, err)
+		poker.AssertLeague(t, export.League, league)
+		if export.Games != nil {
+			t.Errorf("got games %v from a league, want none", export.Games)
+		}
+	})
+
+	t.Run("a spreadsheet only needs names and wins", func(t *testing.T) {
+		export, err := poker.ReadLeagueExport(strings.NewReader("Name,Wins\nCleo,3\nRuth,\n"), poker.CSVFormat)
+		assertNoError(t, err)
+		poker.AssertLeague(t, export.League, poker.League{poker.NewPlayer("Cleo", 3, 3), poker.NewPlayer("Ruth", 0, 0)})
+	})
+
+	t.Run("leaves out games that haven't finished", func(t *testing.T) {
+		store := poker.NewInMemoryPlayerStore()
+		assertNoError(t, store.RecordGame(games[1]))
+		_, err := store.StartGame(poker.GameResult{Players: []string{"Ruth", "Cleo"}})
+		assertNoError(t, err)
+
+		export := poker.ExportLeague(store)
+		if len(export.Games) != 1 || export.Games[0].ID != "b2" {
+			t.Errorf("got games %+v, want only b2", export.Games)
+		}
+	})
+
+	t.Run("rejects CSV it can't read", func(t *testing.T) {
+		for _, csv := range []string{"", "colour\nred\n", "name,wins\nCleo,lots\n", "id,winner\na1,\n"} {
+			if _, err := poker.ReadLeagueExport(strings.NewReader(csv), poker.CSVFormat); err == nil {
+				t.Errorf("expected an error reading %q", csv)
+			}
+		}
+	})
+}
+
+func TestImportLeague(t *testing.T) {
+	games := poker.GameHistory{
+		{ID: "a1", Winner: "Cleo", Players: []string{"Cleo", "ruth"}},
+		{ID: "b2", Winner: "Chris", Players: []string{"Chris", "Cleo"}},
+	}
+
+	newStore := func(t *testing.T) *poker.InMemoryPlayerStore {
+		store := poker.NewInMemoryPlayerStore()
+		assertNoError(t, store.RecordGame(poker.GameResult{ID: "a1", Winner: "Ruth", Players: []string{"Ruth", "Cleo"}}))
+		return store
+	}
+
+	t.Run("imports games it doesn't have", func(t *testing.T) {
+		store := newStore(t)
+
+		report, err := poker.ImportLeague(store, poker.LeagueExport{Games: games}, false)
+		assertNoError(t, err)
+
+		if report.Games != 1 || !reflect.DeepEqual(report.Duplicates, []string{"a1"}) {
+			t.Errorf("got %d games and duplicates %v, want 1 and [a1]", report.Games, report.Duplicates)
+		}
+		if got := len(store.GetGames()); got != 2 {
+			t.Errorf("got %d games in the store, want 2", got)
+		}
+		assertStoreScore(t, store, "Chris", 1)
+	})
+
+	t.Run("a dry run reports conflicts without importing", func(t *testing.T) {
+		store := newStore(t)
+
+		more := []poker.GameResult{{ID: "c3", Winner: "ruth"}, {ID: "d4", Winner: "cleo "}}
+		report, err := poker.ImportLeague(store, poker.LeagueExport{Games: append(games, more...)}, true)
+		assertNoError(t, err)
+
+		if !report.DryRun || report.Games != 3 {
+			t.Errorf("got %+v, want a dry run of 3 games", report)
+		}
+		var conflicts []string
+		for _, c := range report.Conflicts {
+			conflicts = append(conflicts, c.Name+" "+c.Reason)
+		}
+		want := []string{
+			"Cleo already has 0 wins from 1 games",
+			"ruth is already in the league as Ruth, with 1 wins from 1 games",
+		}
+		if !reflect.DeepEqual(conflicts, want) {
+			t.Errorf("got conflicts %q, want %q", conflicts, want)
+		}
+		if got := len(store.GetGames()); got != 1 {
+			t.Errorf("got %d games in the store after a dry run, want 1", got)
+		}
+	})
+
+	t.Run("a league without games brings its wins", func(t *testing.T) {
+		store := newStore(t)
+		league := poker.League{poker.NewPlayer("Cleo", 2, 5), poker.NewPlayer("Pepper", 1, 1)}
+
+		report, err := poker.ImportLeague(store, poker.LeagueExport{League: league}, false)
+		assertNoError(t, err)
+
+		if report.Wins != 3 || report.Games != 0 {
+			t.Errorf("got %+v, want 3 wins and no games", report)
+		}
+		assertStoreScore(t, store, "Cleo", 2)
+		assertStoreScore(t, store, "Pepper", 1)
+	})
+
+	t.Run("stops at players not on the roster", func(t *testing.T) {
+		store := poker.NewRosterPlayerStore(newStore(t))
+
+		report, err := poker.ImportLeague(store, poker.LeagueExport{Games: games}, false)
+		if err == nil || !strings.Contains(err.Error(), "Chris is not on the roster") {
+			t.Errorf("got error %v, want Chris not on the roster", err)
+		}
+		if report.Games != 0 || len(store.GetGames()) != 1 {
+			t.Errorf("got %+v and %v, want nothing imported", report, store.GetGames())
+		}
+	})
+}
+
+func assertStoreScore(t *testing.T, store poker.PlayerStore, name string, want int) {
+	t.Helper()
+	if got := playerWins(store, name); got != want {
+		t.Errorf("got score %d for %s, want %d", got, name, want)
+	}
+}
@@ -1,8 +1,26 @@
 package poker
 
-import "io"
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrGameOver is returned for finishing a game that has already finished.
+var ErrGameOver = errors.New("the game has already finished")
 
 type Game interface {
-	Start(numberOfPlayers int, alertsDestination io.Writer)
-	Finish(winner string)
+	// Start begins a game played with the blind structure, or the
+	// DefaultBlindStructure if it has no levels. players are their names, if
+	// known. Its blind alerts stop once ctx is done, or the game finishes.
+	Start(ctx context.Context, numberOfPlayers int, blinds BlindStructure, alertsDestination io.Writer, players ...string) (Tournament, error)
+}
+
+// Tournament is a game being played. Its clock adjusts the blinds until it
+// is finished.
+type Tournament interface {
+	TournamentClock
+	// Finish records the result of the game, the winner first and then the
+	// other players in the order they finished, if known.
+	Finish(winner string, runnersUp ...string) error
 }
@@ -0,0 +1,142 @@
+package poker
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNoWinner is returned for a game result recorded without a winner.
+var ErrNoWinner = errors.New("game result has no winner")
+
+// ErrNoPlayers is returned for starting a game without naming its players.
+var ErrNoPlayers = errors.New("game has no named players")
+
+// GameResult is the record of one game. A game started with named players is
+// recorded as soon as it starts, with no Winner or FinishedAt until it
+// finishes. Wins recorded on their own, without a game being played through
+// TexasHoldem, only have a Winner and FinishedAt.
+type GameResult struct {
+	ID              string    `json:"id"`
+	StartedAt       time.Time `json:"startedAt"`
+	FinishedAt      time.Time `json:"finishedAt"`
+	NumberOfPlayers int       `json:"numberOfPlayers,omitempty"`
+	Players         []string  `json:"players,omitempty"`
+	FinishingOrder  []string  `json:"finishingOrder,omitempty"`
+	Winner          string    `json:"winner"`
+	FinalBlind      int       `json:"finalBlind,omitempty"`
+	Season          string    `json:"season,omitempty"`
+}
+
+// GameHistory is every game recorded by a store, oldest first.
+type GameHistory []GameResult
+
+// Finished reports whether the game has a result, rather than being still
+// played or abandoned.
+func (g GameResult) Finished() bool {
+	return g.Winner != ""
+}
+
+// Participants is everyone known to have played in the game, winner first.
+func (g GameResult) Participants() []string {
+	var names []string
+	for _, group := range [][]string{{g.Winner}, g.FinishingOrder, g.Players} {
+		for _, name := range group {
+			if name != "" && !contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// League works out everyone's record from the history, in the order players
+// first appear.
+func (h GameHistory) League() League {
+	tally := newLeagueTally(League{})
+	for _, game := range h {
+		tally.record(game)
+	}
+	return tally.league
+}
+
+// Finished returns the games that have a result.
+func (h GameHistory) Finished() GameHistory {
+	games := GameHistory{}
+	for _, game := range h {
+		if game.Finished() {
+			games = append(games, game)
+		}
+	}
+	return games
+}
+
+// ForPlayer returns the games name played in.
+func (h GameHistory) ForPlayer(name string) GameHistory {
+	games := GameHistory{}
+	for _, game := range h {
+		if contains(game.Participants(), name) {
+			games = append(games, game)
+		}
+	}
+	return games
+}
+
+// contains reports whether names has anyone who is the same player as name.
+func contains(names []string, name string) bool {
+	key := PlayerKey(name)
+	for _, n := range names {
+		if PlayerKey(n) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// completeResult checks result has a winner and fills in its ID and finish
+// time if they are missing.
+func completeResult(result GameResult) (GameResult, error) {
+	if result.Winner == "" {
+		return result, ErrNoWinner
+	}
+	if result.ID == "" {
+		result.ID = newGameID()
+	}
+	if result.FinishedAt.IsZero() {
+		result.FinishedAt = time.Now().UTC()
+	}
+	return result, nil
+}
+
+// startedGame checks game names its players and fills in its ID if it is
+// missing. Anything it says about how the game finished is cleared.
+func startedGame(game GameResult) (GameResult, error) {
+	if len(game.Players) == 0 {
+		return game, ErrNoPlayers
+	}
+	if game.ID == "" {
+		game.ID = newGameID()
+	}
+	game.Winner, game.FinishingOrder, game.FinishedAt, game.FinalBlind = "", nil, time.Time{}, 0
+	return game, nil
+}
+
+// completeResults completes every game, or reports the first that can't be.
+func completeResults(games GameHistory) (GameHistory, error) {
+	completed := make(GameHistory, len(games))
+	for i, game := range games {
+		var err error
+		if completed[i], err = completeResult(game); err != nil {
+			return nil, fmt.Errorf("problem with game %d of %d, %w", i+1, len(games), err)
+		}
+	}
+	return completed, nil
+}
+
+func newGameID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -4,20 +4,140 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 )
 
 type League []Player
 
+// Find returns the player name refers to, however its case or spacing
+// differ from the name they are shown by.
 func (l League) Find(name string) *Player {
-
+	key := PlayerKey(name)
 	for i, p := range l {
-		if p.Name == name {
+		if PlayerKey(p.Name) == key {
 			return &l[i]
 		}
 	}
 	return nil
 }
 
+// leagueTally works a league out game by game. It keeps where each player
+// is in the league by their PlayerKey, so recording a game only has to
+// normalise the names in it.
+type leagueTally struct {
+	league    League
+	positions map[string]int
+}
+
+// newLeagueTally carries on from league, which it takes over.
+func newLeagueTally(league League) *leagueTally {
+	t := &leagueTally{league: league, positions: make(map[string]int, len(league))}
+	for i, player := range league {
+		t.positions[PlayerKey(player.Name)] = i
+	}
+	return t
+}
+
+// find returns the player with the given PlayerKey, or nil if there isn't
+// one.
+func (t *leagueTally) find(key string) *Player {
+	if i, ok := t.positions[key]; ok {
+		return &t.league[i]
+	}
+	return nil
+}
+
+// add puts player at the end of the league. They mustn't be in it already.
+func (t *leagueTally) add(player Player) {
+	t.positions[PlayerKey(player.Name)] = len(t.league)
+	t.league = append(t.league, player)
+}
+
+// clone returns a copy that can be changed without affecting t.
+func (t *leagueTally) clone() *leagueTally {
+	positions := make(map[string]int, len(t.positions))
+	for key, i := range t.positions {
+		positions[key] = i
+	}
+	return &leagueTally{league: append(League{}, t.league...), positions: positions}
+}
+
+// record adds result to the league: a game played for everyone who took
+// part, a win for the winner and new ratings for them all. A game that hasn't
+// finished is only a game played. Players new to the league are added.
+func (t *leagueTally) record(result GameResult) {
+	winner := PlayerKey(result.Winner)
+	names := result.Participants()
+	keys := make([]string, len(names))
+	for i, name := range names {
+		keys[i] = PlayerKey(name)
+		player := t.find(keys[i])
+		if player == nil {
+			t.add(NewPlayer(name, 0, 0))
+			player = &t.league[len(t.league)-1]
+		}
+
+		player.GamesPlayed++
+		if result.Finished() && keys[i] == winner {
+			player.Wins++
+		}
+		player.updateRecord()
+	}
+
+	if result.Finished() {
+		t.rate(result, keys)
+	}
+}
+
+// unrecord takes a game that hadn't finished back out of the league, so it
+// can be recorded again with its result.
+func (t *leagueTally) unrecord(started GameResult) {
+	for _, name := range started.Participants() {
+		if player := t.find(PlayerKey(name)); player != nil {
+			player.GamesPlayed--
+			player.updateRecord()
+		}
+	}
+}
+
+// Sorted returns a copy of the league ordered by wins, most first, with
+// players on the same number of wins in name order.
+func (l League) Sorted() League {
+	league := make(League, len(l))
+	copy(league, l)
+
+	sort.Slice(league, func(i, j int) bool {
+		if league[i].Wins != league[j].Wins {
+			return league[i].Wins > league[j].Wins
+		}
+		return league[i].Name < league[j].Name
+	})
+	return league
+}
+
+// RankedByWinRate returns a copy of the league holding only players who have
+// played at least minGames, ordered by win rate, best first. Ties go to the
+// player with more wins, then by name.
+func (l League) RankedByWinRate(minGames int) League {
+	league := League{}
+	for _, player := range l {
+		if player.GamesPlayed >= minGames {
+			league = append(league, player)
+		}
+	}
+
+	sort.Slice(league, func(i, j int) bool {
+		if league[i].WinRate != league[j].WinRate {
+			return league[i].WinRate > league[j].WinRate
+		}
+		if league[i].Wins != league[j].Wins {
+			return league[i].Wins > league[j].Wins
+		}
+		return league[i].Name < league[j].Name
+	})
+	return league
+}
+
 func NewLeague(rdr io.Reader) ([]Player, error) {
 	var league []Player
 	err := json.NewDecoder(rdr).Decode(&league)
@@ -0,0 +1,251 @@
+package poker
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrBadLeagueQuery is returned for a LeagueQuery that can't be answered.
+var ErrBadLeagueQuery = errors.New("bad league query")
+
+// LeagueQuery asks a store for one page of a league.
+type LeagueQuery struct {
+	// Season is the season whose league to query, or empty for all time.
+	Season string
+	// Sort is "wins", "name", "winrate" or "rating", and "wins" if empty.
+	Sort string
+	// Order is "asc" or "desc". Names are in ascending order by default and
+	// everything else descending.
+	Order string
+	// MinGames leaves out players who have played fewer games.
+	MinGames int
+	// Prefix leaves out players whose names don't start with it, ignoring
+	// case and spacing.
+	Prefix string
+	// Limit is the most players in the page, or 0 for no limit.
+	Limit int
+	// Offset is how many players to skip. Cursor, taken from the
+	// NextCursor of an earlier page, starts the page after the last player
+	// on that one instead.
+	Offset int
+	Cursor string
+}
+
+// LeaguePage is the part of a league a LeagueQuery asked for.
+type LeaguePage struct {
+	Players League
+	// Total is how many players the query matches, over every page.
+	Total int
+	// Offset is how many of them come before this page.
+	Offset int
+	// NextCursor is the Cursor of the page after this one, or empty if this
+	// is the last.
+	NextCursor string
+}
+
+// leagueOrders sort a league from the wins order GetLeague returns it in.
+var leagueOrders = map[string]func(League) League{
+	"wins":    func(l League) League { return l },
+	"name":    League.sortedByName,
+	"winrate": func(l League) League { return l.RankedByWinRate(0) },
+	"rating":  League.RankedByRating,
+}
+
+// leagueIndex answers queries of a league, sorting it each way only the
+// first time it is asked to. Each player's PlayerKey is worked out once, when
+// the index is made. It is safe for concurrent use, but the league must not
+// change under it: make a new index instead.
+type leagueIndex struct {
+	league League
+	// keys are the PlayerKeys of the players in the league, by name.
+	keys map[string]string
+
+	mu     sync.Mutex
+	orders map[string]leagueOrder
+}
+
+// seasonIndexes keeps the index of each season's league once it has been
+// queried, for as long as the games it was worked out from stay the same:
+// make a new one when they change. It is safe for concurrent use.
+type seasonIndexes struct {
+	mu      sync.Mutex
+	indexes map[string]*leagueIndex
+}
+
+func newSeasonIndexes() *seasonIndexes {
+	return &seasonIndexes{indexes: map[string]*leagueIndex{}}
+}
+
+// index returns the index of the season's league, working the league out
+// with league the first time it is asked for.
+func (s *seasonIndexes) index(id string, league func() (League, error)) (*leagueIndex, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if index, ok := s.indexes[id]; ok {
+		return index, nil
+	}
+	l, err := league()
+	if err != nil {
+		return nil, err
+	}
+	s.indexes[id] = newLeagueIndex(l.Sorted())
+	return s.indexes[id], nil
+}
+
+// leagueOrder is the league sorted one way, with the PlayerKey of each player
+// in the same order and where each player is, by PlayerKey.
+type leagueOrder struct {
+	league    League
+	keys      []string
+	positions map[string]int
+}
+
+// newLeagueIndex indexes league, which must be sorted by wins.
+func newLeagueIndex(league League) *leagueIndex {
+	keys := make(map[string]string, len(league))
+	for _, player := range league {
+		keys[player.Name] = PlayerKey(player.Name)
+	}
+	return &leagueIndex{
+		league: league,
+		keys:   keys,
+		orders: map[string]leagueOrder{},
+	}
+}
+
+// sorted returns the league in the given order.
+func (i *leagueIndex) sorted(by string) leagueOrder {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if order, ok := i.orders[by]; ok {
+		return order
+	}
+
+	order := leagueOrder{league: leagueOrders[by](i.league)}
+	order.keys = make([]string, len(order.league))
+	order.positions = make(map[string]int, len(order.league))
+	for n, player := range order.league {
+		order.keys[n] = i.keys[player.Name]
+		order.positions[order.keys[n]] = n
+	}
+	i.orders[by] = order
+	return order
+}
+
+func (i *leagueIndex) query(q LeagueQuery) (LeaguePage, error) {
+	by := q.Sort
+	if by == "" {
+		by = "wins"
+	}
+	if _, ok := leagueOrders[by]; !ok {
+		return LeaguePage{}, fmt.Errorf("%w: cannot sort league by %q", ErrBadLeagueQuery, q.Sort)
+	}
+
+	var reverse bool
+	switch q.Order {
+	case "":
+	case "asc":
+		reverse = by != "name"
+	case "desc":
+		reverse = by == "name"
+	default:
+		return LeaguePage{}, fmt.Errorf("%w: order must be asc or desc, got %q", ErrBadLeagueQuery, q.Order)
+	}
+
+	switch {
+	case q.Limit < 0, q.Offset < 0, q.MinGames < 0:
+		return LeaguePage{}, fmt.Errorf("%w: limit, offset and minGames can't be negative", ErrBadLeagueQuery)
+	case q.Cursor != "" && q.Offset > 0:
+		return LeaguePage{}, fmt.Errorf("%w: use a cursor or an offset, not both", ErrBadLeagueQuery)
+	}
+
+	order := i.sorted(by)
+	league, keys := order.league, order.keys
+	prefix := PlayerKey(q.Prefix)
+
+	// Players sorted by name are sorted by key too, so those with the prefix
+	// are all together.
+	lo, hi := 0, len(league)
+	if by == "name" && prefix != "" {
+		lo = sort.SearchStrings(keys, prefix)
+		hi = lo + sort.Search(len(keys)-lo, func(n int) bool {
+			return !strings.HasPrefix(keys[lo+n], prefix)
+		})
+	}
+
+	// step is how far into the range a position is, in the order asked for.
+	step := func(position int) int {
+		if reverse {
+			return hi - 1 - position
+		}
+		return position - lo
+	}
+
+	pastStart := func(n, match int) bool { return match >= q.Offset }
+	if q.Cursor != "" {
+		position, ok := order.positions[decodeLeagueCursor(q.Cursor)]
+		if !ok {
+			return LeaguePage{}, fmt.Errorf("%w: cursor %q is not in the league", ErrBadLeagueQuery, q.Cursor)
+		}
+		after := step(position)
+		pastStart = func(n, _ int) bool { return n > after }
+	}
+
+	page := LeaguePage{Players: League{}}
+	more := false
+	for n := 0; n < hi-lo; n++ {
+		position := lo + n
+		if reverse {
+			position = hi - 1 - n
+		}
+		player := league[position]
+		if player.GamesPlayed < q.MinGames || !strings.HasPrefix(keys[position], prefix) {
+			continue
+		}
+
+		page.Total++
+		switch {
+		case len(page.Players) == 0 && !pastStart(n, page.Total-1):
+			page.Offset++
+		case q.Limit == 0 || len(page.Players) < q.Limit:
+			page.Players = append(page.Players, player)
+		default:
+			more = true
+		}
+	}
+
+	if more {
+		page.NextCursor = encodeLeagueCursor(page.Players[len(page.Players)-1].Name)
+	}
+	return page, nil
+}
+
+func encodeLeagueCursor(name string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(PlayerKey(name)))
+}
+
+func decodeLeagueCursor(cursor string) string {
+	key, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return ""
+	}
+	return string(key)
+}
+
+// sortedByName returns a copy of the league in name order, ignoring case and
+// spacing.
+func (l League) sortedByName() League {
+	league := make(League, len(l))
+	copy(league, l)
+
+	sort.SliceStable(league, func(i, j int) bool {
+		return PlayerKey(league[i].Name) < PlayerKey(league[j].Name)
+	})
+	return league
+}
@@ -0,0 +1,159 @@
+package poker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LeagueFactory opens the store and game for a new league with the given ID.
+type LeagueFactory func(id string) (PlayerStore, Game, error)
+
+var (
+	ErrLeagueExists    = errors.New("league already exists")
+	ErrInvalidLeagueID = errors.New("league IDs may only use letters, numbers, - and _")
+)
+
+var leagueIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// leagues are the named leagues a PlayerServer hosts as well as its own,
+// each served by a PlayerServer of their own.
+type leagues struct {
+	mu        sync.RWMutex
+	servers   map[string]*PlayerServer
+	newLeague LeagueFactory
+}
+
+// AddLeague serves another league under /leagues/{id}/, with the same routes
+// as the server's own league but backed by store and game.
+func (p *PlayerServer) AddLeague(id string, store PlayerStore, game Game) error {
+	p.leagues.mu.Lock()
+	defer p.leagues.mu.Unlock()
+
+	if err := p.checkNewLeague(id); err != nil {
+		return err
+	}
+	p.addLeague(id, store, game)
+	return nil
+}
+
+// CreateLeaguesWith lets leagues be created by posting them to /leagues,
+// using newLeague to open each one's store and game.
+func (p *PlayerServer) CreateLeaguesWith(newLeague LeagueFactory) {
+	p.leagues.mu.Lock()
+	defer p.leagues.mu.Unlock()
+	p.leagues.newLeague = newLeague
+}
+
+// LeagueIDs returns the IDs of the named leagues, in order.
+func (p *PlayerServer) LeagueIDs() []string {
+	p.leagues.mu.RLock()
+	defer p.leagues.mu.RUnlock()
+
+	ids := []string{}
+	for id := range p.leagues.servers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// checkNewLeague must be called with leagues.mu held.
+func (p *PlayerServer) checkNewLeague(id string) error {
+	if !leagueIDPattern.MatchString(id) {
+		return fmt.Errorf("%w, got %q", ErrInvalidLeagueID, id)
+	}
+	if _, ok := p.leagues.servers[id]; ok {
+		return fmt.Errorf("%w: %s", ErrLeagueExists, id)
+	}
+	return nil
+}
+
+// addLeague must be called with leagues.mu held.
+func (p *PlayerServer) addLeague(id string, store PlayerStore, game Game) {
+	league := &PlayerServer{store: store, game: game, template: p.template, blinds: p.blinds, clock: p.clock}
+	league.Handler = league.routes()
+	p.leagues.servers[id] = league
+}
+
+func (p *PlayerServer) createLeague(id string) error {
+	p.leagues.mu.Lock()
+	defer p.leagues.mu.Unlock()
+
+	if err := p.checkNewLeague(id); err != nil {
+		return err
+	}
+
+	store, game, err := p.leagues.newLeague(id)
+	if err != nil {
+		return fmt.Errorf("problem opening league %s, %v", id, err)
+	}
+	p.addLeague(id, store, game)
+	return nil
+}
+
+// leaguesHandler lists the named leagues, or creates one when a league like
+// {"id": "tuesday"} is posted to it.
+func (p *PlayerServer) leaguesHandler(w http.ResponseWriter, r *http.Request) {
+	p.leagues.mu.RLock()
+	canCreate := p.leagues.newLeague != nil
+	p.leagues.mu.RUnlock()
+
+	switch {
+	case r.Method == http.MethodGet:
+		w.Header().Set("content-type", "application/json")
+		json.NewEncoder(w).Encode(p.LeagueIDs())
+	case r.Method == http.MethodPost && canCreate:
+		p.processNewLeague(w, r)
+	case canCreate:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	default:
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (p *PlayerServer) processNewLeague(w http.ResponseWriter, r *http.Request) {
+	var league struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&league); err != nil {
+		http.Error(w, `expected a league like {"id": "tuesday"}`, http.StatusBadRequest)
+		return
+	}
+
+	err := p.createLeague(league.ID)
+	switch {
+	case errors.Is(err, ErrInvalidLeagueID):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrLeagueExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.Header().Set("Location", "/leagues/"+league.ID+"/league")
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+// namedLeagueHandler passes /leagues/{id}/... on to the league's own routes.
+func (p *PlayerServer) namedLeagueHandler(w http.ResponseWriter, r *http.Request) {
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/leagues/"), "/", 2)
+	id := parts[0]
+
+	p.leagues.mu.RLock()
+	league, ok := p.leagues.servers[id]
+	p.leagues.mu.RUnlock()
+
+	if !ok || len(parts) < 2 {
+		http.NotFound(w, r)
+		return
+	}
+	http.StripPrefix("/leagues/"+id, league).ServeHTTP(w, r)
+}
@@ -0,0 +1,267 @@
+package poker
+
+import (
+	"fmt"
+	"time"
+)
+
+// ledger is the game history kept in memory by the stores that load
+// everything at startup, along with the league derived from it and indexes
+// of the league and of each season's league. It is not safe for concurrent use; the stores guard it with
+// their own locks.
+type ledger struct {
+	games   GameHistory
+	seasons []Season
+	roster  []string
+	tally   *leagueTally
+	index   *leagueIndex
+	// seasonIndexes are made as seasons are queried, and start again
+	// whenever the games change.
+	seasonIndexes *seasonIndexes
+}
+
+func newLedger(db Database) *ledger {
+	l := &ledger{
+		games:   db.Games,
+		seasons: db.Seasons,
+		roster:  db.Roster,
+	}
+	if l.games == nil {
+		l.games = GameHistory{}
+	}
+	if l.seasons == nil {
+		l.seasons = []Season{}
+	}
+	if l.roster == nil {
+		l.roster = []string{}
+	}
+	l.setLeague(newLeagueTally(l.games.League()))
+	return l
+}
+
+// database is what the ledger saves, stamped with metadata.
+func (l *ledger) database(metadata DatabaseMetadata) Database {
+	return Database{
+		Version:  DatabaseVersion,
+		Metadata: metadata,
+		Games:    l.games,
+		Seasons:  l.seasons,
+		Roster:   l.roster,
+	}
+}
+
+// clone returns a copy that can be changed without affecting l, so stores
+// can keep the old state if saving the new one fails.
+func (l *ledger) clone() *ledger {
+	return &ledger{
+		games:   append(GameHistory{}, l.games...),
+		seasons: append([]Season{}, l.seasons...),
+		roster:  append([]string{}, l.roster...),
+		tally:   l.tally.clone(),
+		index:   l.index,
+
+		seasonIndexes: l.seasonIndexes,
+	}
+}
+
+// recordGame adds result to the history, attributing it to the active season
+// if it doesn't already name one and naming its players as they are already
+// known. A result for a game recorded when it started replaces it. It returns
+// the result as recorded.
+func (l *ledger) recordGame(result GameResult) GameResult {
+	result = l.attribute(result)
+
+	if i := l.started(result.ID); i >= 0 {
+		l.tally.unrecord(l.games[i])
+		l.games = append(l.games[:i:i], l.games[i+1:]...)
+	}
+	l.games = append(l.games, result)
+	l.tally.record(result)
+	l.setLeague(l.tally)
+	return result
+}
+
+// importGames adds the games to the history as they are, naming their
+// players as they are already known, or as the first game names them.
+func (l *ledger) importGames(games GameHistory) {
+	book := l.names()
+	for _, game := range games {
+		game = game.withDisplayNames(book.display)
+		for _, name := range game.Participants() {
+			book.add(name)
+		}
+
+		l.games = append(l.games, game)
+		l.tally.record(game)
+	}
+	l.setLeague(l.tally)
+}
+
+// attribute puts result in the season its game started in, or the active
+// season if it wasn't recorded when it started, unless it already names one.
+// Its players are given the names they are already shown by.
+func (l *ledger) attribute(result GameResult) GameResult {
+	if i := l.started(result.ID); i >= 0 {
+		if result.Season == "" {
+			result.Season = l.games[i].Season
+		}
+	} else if season, ok := activeSeason(l.seasons); ok && result.Season == "" {
+		result.Season = season.ID
+	}
+	return result.withDisplayNames(l.names().display)
+}
+
+// names is everyone in the league or on the roster, by the names they are
+// shown by.
+func (l *ledger) names() nameBook {
+	book := nameBook{}
+	for _, player := range l.tally.league {
+		book.add(player.Name)
+	}
+	for _, name := range l.roster {
+		book.add(name)
+	}
+	return book
+}
+
+// started returns where the unfinished game with the given ID is in the
+// history, or -1 if there isn't one.
+func (l *ledger) started(id string) int {
+	if id == "" {
+		return -1
+	}
+	for i := len(l.games) - 1; i >= 0; i-- {
+		if l.games[i].ID == id && !l.games[i].Finished() {
+			return i
+		}
+	}
+	return -1
+}
+
+func (l *ledger) startSeason(id string, at time.Time) error {
+	seasons, err := startSeason(l.seasons, id, at)
+	if err != nil {
+		return err
+	}
+	l.seasons = seasons
+	return nil
+}
+
+func (l *ledger) closeSeason(at time.Time) error {
+	if _, ok := activeSeason(l.seasons); !ok {
+		return ErrNoActiveSeason
+	}
+	l.seasons = closeSeason(l.seasons, at)
+	return nil
+}
+
+// sortedLeague returns the league sorted by wins.
+func (l *ledger) sortedLeague() League {
+	return append(League{}, l.index.league...)
+}
+
+func (l *ledger) queryLeague(query LeagueQuery) (LeaguePage, error) {
+	if query.Season == "" {
+		return l.index.query(query)
+	}
+	index, err := l.seasonIndex(query.Season)
+	if err != nil {
+		return LeaguePage{}, err
+	}
+	return index.query(query)
+}
+
+// seasonLeague returns the season's league sorted by wins.
+func (l *ledger) seasonLeague(id string) (League, error) {
+	index, err := l.seasonIndex(id)
+	if err != nil {
+		return nil, err
+	}
+	return append(League{}, index.league...), nil
+}
+
+func (l *ledger) seasonIndex(id string) (*leagueIndex, error) {
+	if _, ok := findSeason(l.seasons, id); !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSeason, id)
+	}
+	return l.seasonIndexes.index(id, func() (League, error) {
+		return l.games.ForSeason(id).League(), nil
+	})
+}
+
+func (l *ledger) renamePlayer(from, to string) error {
+	if err := checkRename(l.tally.league, from, to, false); err != nil {
+		return err
+	}
+	l.setGames(l.games.replacePlayer(from, to))
+	l.roster = replaceName(l.roster, from, to)
+	return nil
+}
+
+func (l *ledger) mergePlayers(from, into string) error {
+	if err := checkRename(l.tally.league, from, into, true); err != nil {
+		return err
+	}
+	l.setGames(l.games.replacePlayer(from, into))
+	l.roster = replaceName(l.roster, from, into)
+	return nil
+}
+
+func (l *ledger) deletePlayer(name string) error {
+	if err := checkDelete(l.tally.league, name); err != nil {
+		return err
+	}
+	l.setGames(l.games.withoutPlayer(name))
+	l.roster = removeName(l.roster, name)
+	return nil
+}
+
+func (l *ledger) registerPlayer(name string) error {
+	if err := checkRegistration(l.fullRoster(), name); err != nil {
+		return err
+	}
+	l.roster = append(l.roster, cleanName(name))
+	return nil
+}
+
+func (l *ledger) fullRoster() []string {
+	return fullRoster(l.roster, l.tally.league)
+}
+
+// setGames replaces the history and works the league out again from it.
+func (l *ledger) setGames(games GameHistory) {
+	l.games = games
+	l.setLeague(newLeagueTally(games.League()))
+}
+
+// setLeague replaces the league and its index, and forgets the indexes of
+// the seasons' leagues.
+func (l *ledger) setLeague(tally *leagueTally) {
+	l.tally = tally
+	l.index = newLeagueIndex(tally.league.Sorted())
+	l.seasonIndexes = newSeasonIndexes()
+}
+
+// player returns name's record, and whether they are in the league or on the
+// roster.
+func (l *ledger) player(name string) (Player, bool) {
+	if player := l.tally.find(PlayerKey(name)); player != nil {
+		return *player, true
+	}
+	for _, registered := range l.roster {
+		if SamePlayer(registered, name) {
+			return NewPlayer(registered, 0, 0), true
+		}
+	}
+	return Player{}, false
+}
+
+func (l *ledger) score(name string) int {
+	player := l.tally.find(PlayerKey(name))
+
+	if player != nil {
+		return player.Wins
+	}
+
+	return 0
+}
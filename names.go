@@ -0,0 +1,65 @@
+package poker
+
+import (
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// PlayerKey is who a name refers to. Names that differ only in case, Unicode
+// form or spacing, like "Chris", "chris" and " Chris ", have the same key.
+func PlayerKey(name string) string {
+	return strings.Join(strings.Fields(cases.Fold().String(norm.NFKC.String(name))), " ")
+}
+
+// SamePlayer reports whether a and b name the same player.
+func SamePlayer(a, b string) bool {
+	return PlayerKey(a) == PlayerKey(b)
+}
+
+// cleanName is how a new player's name is shown: in its composed Unicode form
+// with spacing tidied up, but otherwise as it was typed.
+func cleanName(name string) string {
+	return strings.Join(strings.Fields(norm.NFC.String(name)), " ")
+}
+
+// nameBook holds the name each player is shown by, which is the first one
+// added for them.
+type nameBook map[string]string
+
+func (b nameBook) add(name string) {
+	key := PlayerKey(name)
+	if _, ok := b[key]; !ok && key != "" {
+		b[key] = cleanName(name)
+	}
+}
+
+func (b nameBook) display(name string) string {
+	if shown, ok := b[PlayerKey(name)]; ok {
+		return shown
+	}
+	return cleanName(name)
+}
+
+// withDisplayNames returns the game with each name swapped for display(name),
+// keeping only the first mention of anyone named twice.
+func (g GameResult) withDisplayNames(display func(string) string) GameResult {
+	g.Winner = display(g.Winner)
+	g.Players = displayAll(g.Players, display)
+	g.FinishingOrder = displayAll(g.FinishingOrder, display)
+	return g
+}
+
+func displayAll(names []string, display func(string) string) []string {
+	if names == nil {
+		return nil
+	}
+	shown := []string{}
+	for _, name := range names {
+		if name = display(name); !contains(shown, name) {
+			shown = append(shown, name)
+		}
+	}
+	return shown
+}
@@ -0,0 +1,40 @@
+package poker_test
+
+import (
+	"testing"
+
+	poker "github.com/tsugoshi/learn-go-application"
+)
+
+func TestSamePlayer(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want bool
+	}{
+		{"Chris", "Chris", true},
+		{"Chris", "chris", true},
+		{"Chris", "  Chris ", true},
+		{"Mary Ann", "mary   ann", true},
+		{"José", "José", true},
+		{"Straße", "STRASSE", true},
+		{"Chris", "Chrissy", false},
+		{"Mary Ann", "MaryAnn", false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.a+" and "+c.b, func(t *testing.T) {
+			if got := poker.SamePlayer(c.a, c.b); got != c.want {
+				t.Errorf("got %v for %q and %q, wanted %v", got, c.a, c.b, c.want)
+			}
+		})
+	}
+}
+
+func TestLeagueFind(t *testing.T) {
+	league := poker.League{poker.NewPlayer("Chris", 1, 1)}
+
+	player := league.Find(" CHRIS")
+	if player == nil || player.Name != "Chris" {
+		t.Errorf("expected to find Chris, got %v", player)
+	}
+}
@@ -0,0 +1,117 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrUnknownPlayer = errors.New("no such player")
+	ErrPlayerExists  = errors.New("player already exists")
+	ErrBadRename     = errors.New("players must be renamed to a different, non-empty name")
+	ErrNoPlayerName  = errors.New("player needs a name")
+	ErrPlayerHasWins = errors.New("player has won games, merge them into another player instead")
+)
+
+// replacePlayer returns the game with from swapped for to wherever they
+// appear, keeping only the first mention of to if both played.
+func (g GameResult) replacePlayer(from, to string) GameResult {
+	if SamePlayer(g.Winner, from) {
+		g.Winner = to
+	}
+	g.Players = replaceName(g.Players, from, to)
+	g.FinishingOrder = replaceName(g.FinishingOrder, from, to)
+	return g
+}
+
+// withoutPlayer returns the game with name taken out of it. name mustn't
+// have won it.
+func (g GameResult) withoutPlayer(name string) GameResult {
+	g.Players = removeName(g.Players, name)
+	g.FinishingOrder = removeName(g.FinishingOrder, name)
+	return g
+}
+
+// replacePlayer returns a copy of the history with from swapped for to.
+func (h GameHistory) replacePlayer(from, to string) GameHistory {
+	games := GameHistory{}
+	for _, game := range h {
+		games = append(games, game.replacePlayer(from, to))
+	}
+	return games
+}
+
+// withoutPlayer returns a copy of the history with name taken out of every
+// game. name mustn't have won any of them.
+func (h GameHistory) withoutPlayer(name string) GameHistory {
+	games := GameHistory{}
+	for _, game := range h {
+		games = append(games, game.withoutPlayer(name))
+	}
+	return games
+}
+
+// checkDelete reports why name can't be deleted from league. Deleting a
+// player who has won games would take away the games everyone else played
+// in them, so they can only be merged into someone else.
+func checkDelete(league League, name string) error {
+	player := league.Find(name)
+	if player == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownPlayer, name)
+	}
+	if player.Wins > 0 {
+		return fmt.Errorf("%w: %s has %d wins", ErrPlayerHasWins, player.Name, player.Wins)
+	}
+	return nil
+}
+
+// checkRename reports why from can't be renamed to, or merged into, to in
+// league. Merging needs to to be another player already; renaming needs them
+// not to be, though a player can be renamed to change how their name is shown.
+func checkRename(league League, from, to string, merge bool) error {
+	same := SamePlayer(from, to)
+	if PlayerKey(to) == "" || cleanName(from) == cleanName(to) || (merge && same) {
+		return fmt.Errorf("%w, got %q to %q", ErrBadRename, from, to)
+	}
+	if league.Find(from) == nil {
+		return fmt.Errorf("%w: %s", ErrUnknownPlayer, from)
+	}
+
+	exists := !same && league.Find(to) != nil
+	switch {
+	case merge && !exists:
+		return fmt.Errorf("%w: %s", ErrUnknownPlayer, to)
+	case !merge && exists:
+		return fmt.Errorf("%w: %s", ErrPlayerExists, to)
+	}
+	return nil
+}
+
+func replaceName(names []string, from, to string) []string {
+	if names == nil {
+		return nil
+	}
+	replaced := []string{}
+	for _, name := range names {
+		if SamePlayer(name, from) {
+			name = to
+		}
+		if !contains(replaced, name) {
+			replaced = append(replaced, name)
+		}
+	}
+	return replaced
+}
+
+func removeName(names []string, remove string) []string {
+	if names == nil {
+		return nil
+	}
+	kept := []string{}
+	for _, name := range names {
+		if !SamePlayer(name, remove) {
+			kept = append(kept, name)
+		}
+	}
+	return kept
+}
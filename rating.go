@@ -0,0 +1,107 @@
+package poker
+
+import (
+	"math"
+	"sort"
+)
+
+// InitialRating is the Elo rating every player starts on.
+const InitialRating = 1500.0
+
+// ratingK is the most a rating can move in one game.
+const ratingK = 32.0
+
+// Rating is a player's Elo rating as served on /ratings.
+type Rating struct {
+	Name        string
+	Rating      float64
+	GamesPlayed int
+}
+
+// placings gives each participant their finishing place, 0 for the winner,
+// keyed by PlayerKey.
+// Players missing from the finishing order share the place after it.
+func (g GameResult) placings() map[string]int {
+	last := len(g.FinishingOrder)
+	if last == 0 {
+		last = 1
+	}
+
+	placings := map[string]int{}
+	for _, name := range g.Participants() {
+		placings[PlayerKey(name)] = last
+	}
+	for i, name := range g.FinishingOrder {
+		placings[PlayerKey(name)] = i
+	}
+	placings[PlayerKey(g.Winner)] = 0
+	return placings
+}
+
+// rate updates the ratings of everyone in the game. Each pair of players is
+// scored like a head to head Elo match, with whoever finished higher winning
+// and players on the same place drawing; K is shared between a player's
+// opponents so a game moves a rating no more than a two player one.
+// keys are the PlayerKeys of the game's participants.
+func (t *leagueTally) rate(result GameResult, keys []string) {
+	if len(keys) < 2 {
+		return
+	}
+
+	placings := result.placings()
+	before := make(map[string]float64, len(keys))
+	for _, key := range keys {
+		before[key] = t.find(key).Rating
+	}
+
+	k := ratingK / float64(len(keys)-1)
+	for _, key := range keys {
+		change := 0.0
+		for _, opponent := range keys {
+			if opponent == key {
+				continue
+			}
+			expected := 1 / (1 + math.Pow(10, (before[opponent]-before[key])/400))
+			change += k * (score(placings[key], placings[opponent]) - expected)
+		}
+		t.find(key).Rating = math.Round((before[key]+change)*100) / 100
+	}
+}
+
+func score(place, opponentPlace int) float64 {
+	switch {
+	case place < opponentPlace:
+		return 1
+	case place > opponentPlace:
+		return 0
+	default:
+		return 0.5
+	}
+}
+
+// Ratings returns everyone's rating, highest first, with ties going to the
+// player with more games, then by name.
+func (l League) Ratings() []Rating {
+	ratings := []Rating{}
+	for _, player := range l.RankedByRating() {
+		ratings = append(ratings, Rating{player.Name, player.Rating, player.GamesPlayed})
+	}
+	return ratings
+}
+
+// RankedByRating returns a copy of the league ordered by rating, highest first.
+func (l League) RankedByRating() League {
+	league := make(League, len(l))
+	copy(league, l)
+
+	sort.Slice(league, func(i, j int) bool {
+		if league[i].Rating != league[j].Rating {
+			return league[i].Rating > league[j].Rating
+		}
+		if league[i].GamesPlayed != league[j].GamesPlayed {
+			return league[i].GamesPlayed > league[j].GamesPlayed
+		}
+		return league[i].Name < league[j].Name
+	})
+	return league
+}
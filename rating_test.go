@@ -0,0 +1,73 @@
+package poker_test
+
+import (
+	"reflect"
+	"testing"
+
+	poker "github.com/tsugoshi/learn-go-application"
+)
+
+func TestRatings(t *testing.T) {
+
+	t.Run("a head to head win moves both ratings by half of K", func(t *testing.T) {
+		games := poker.GameHistory{
+			{Winner: "Ruth", Players: []string{"Ruth", "Cleo"}},
+		}
+
+		assertRatings(t, games.League().Ratings(), []poker.Rating{
+			{Name: "Ruth", Rating: 1516, GamesPlayed: 1},
+			{Name: "Cleo", Rating: 1484, GamesPlayed: 1},
+		})
+	})
+
+	t.Run("players are rated by where they finished", func(t *testing.T) {
+		games := poker.GameHistory{
+			{Winner: "Ruth", FinishingOrder: []string{"Ruth", "Cleo", "Chris"}},
+		}
+
+		assertRatings(t, games.League().Ratings(), []poker.Rating{
+			{Name: "Ruth", Rating: 1516, GamesPlayed: 1},
+			{Name: "Cleo", Rating: 1500, GamesPlayed: 1},
+			{Name: "Chris", Rating: 1484, GamesPlayed: 1},
+		})
+	})
+
+	t.Run("wins on their own don't change ratings", func(t *testing.T) {
+		games := poker.GameHistory{
+			{Winner: "Ruth"},
+			{Winner: "Ruth"},
+		}
+
+		assertRatings(t, games.League().Ratings(), []poker.Rating{
+			{Name: "Ruth", Rating: poker.InitialRating, GamesPlayed: 2},
+		})
+	})
+
+	t.Run("the same history always gives the same ratings", func(t *testing.T) {
+		games := poker.GameHistory{
+			{Winner: "Ruth", FinishingOrder: []string{"Ruth", "Cleo", "Chris"}},
+			{Winner: "Chris", Players: []string{"Ruth", "Cleo", "Chris", "Pepper"}},
+			{Winner: "Cleo", FinishingOrder: []string{"Cleo", "Pepper"}},
+		}
+
+		first := games.League().Ratings()
+		for i := 0; i < 10; i++ {
+			assertRatings(t, games.League().Ratings(), first)
+		}
+
+		total := 0.0
+		for _, rating := range first {
+			total += rating.Rating
+		}
+		if total < 4*poker.InitialRating-0.05 || total > 4*poker.InitialRating+0.05 {
+			t.Errorf("expected ratings to add up to %v, got %v", 4*poker.InitialRating, total)
+		}
+	})
+}
+
+func assertRatings(t *testing.T, got, want []poker.Rating) {
+	t.Helper()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got ratings %v, wanted %v", got, want)
+	}
+}
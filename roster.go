@@ -0,0 +1,194 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrNotOnRoster is matched by every NotOnRosterError.
+var ErrNotOnRoster = errors.New("player is not on the roster")
+
+// NotOnRosterError is returned when a game names a player who hasn't been
+// registered, along with the registered names closest to theirs.
+type NotOnRosterError struct {
+	Name        string
+	Suggestions []string
+}
+
+func (e *NotOnRosterError) Error() string {
+	msg := fmt.Sprintf("%s is not on the roster", e.Name)
+	if len(e.Suggestions) > 0 {
+		msg += fmt.Sprintf(", did you mean %s?", strings.Join(e.Suggestions, " or "))
+	}
+	return msg
+}
+
+func (e *NotOnRosterError) Is(target error) bool {
+	return target == ErrNotOnRoster
+}
+
+// RosterPlayerStore only records games between players on the roster, so a
+// typo can't add a new player to the league. Players join the roster with
+// RegisterPlayer; everyone already in the league is on it.
+type RosterPlayerStore struct {
+	PlayerStore
+}
+
+func NewRosterPlayerStore(store PlayerStore) *RosterPlayerStore {
+	return &RosterPlayerStore{store}
+}
+
+func (r *RosterPlayerStore) RecordWin(name string) error {
+	if err := r.checkRoster([]string{name}); err != nil {
+		return err
+	}
+	return r.PlayerStore.RecordWin(name)
+}
+
+func (r *RosterPlayerStore) RecordGame(result GameResult) error {
+	if err := r.checkRoster(result.Participants()); err != nil {
+		return err
+	}
+	return r.PlayerStore.RecordGame(result)
+}
+
+// StartGame checks everyone starting the game is on the roster, so a typo is
+// caught before it is played rather than when its result is recorded.
+func (r *RosterPlayerStore) StartGame(game GameResult) (GameResult, error) {
+	if err := r.checkRoster(game.Participants()); err != nil {
+		return game, err
+	}
+	return r.PlayerStore.StartGame(game)
+}
+
+func (r *RosterPlayerStore) ImportGames(games GameHistory) error {
+	var names []string
+	for _, game := range games {
+		names = append(names, game.Participants()...)
+	}
+	if err := r.checkRoster(names); err != nil {
+		return err
+	}
+	return r.PlayerStore.ImportGames(games)
+}
+
+func (r *RosterPlayerStore) checkRoster(names []string) error {
+	roster := r.GetRoster()
+	for _, name := range names {
+		if !contains(roster, name) {
+			return &NotOnRosterError{Name: name, Suggestions: Suggest(name, roster)}
+		}
+	}
+	return nil
+}
+
+// maxSuggestions is the most names Suggest returns.
+const maxSuggestions = 3
+
+// Suggest returns the names in roster that are a small typo away from name,
+// closest first. Case and spacing are ignored.
+func Suggest(name string, roster []string) []string {
+	type match struct {
+		name     string
+		distance int
+	}
+
+	var matches []match
+	key := PlayerKey(name)
+	for _, candidate := range roster {
+		d := editDistance(key, PlayerKey(candidate))
+		if d <= maxTypos(name) {
+			matches = append(matches, match{candidate, d})
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+
+	suggestions := []string{}
+	for i := 0; i < len(matches) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, matches[i].name)
+	}
+	return suggestions
+}
+
+// maxTypos is how many edits Suggest allows: one for short names, growing
+// with the name's length.
+func maxTypos(name string) int {
+	if n := utf8.RuneCountInString(name) / 3; n > 1 {
+		return n
+	}
+	return 1
+}
+
+// editDistance is the number of inserted, deleted, changed or swapped
+// neighbouring letters it takes to turn a into b.
+func editDistance(a, b string) int {
+	s, t := []rune(a), []rune(b)
+
+	rows := make([][]int, len(s)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(t)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			rows[i][j] = smallest(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				rows[i][j] = smallest(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(s)][len(t)]
+}
+
+func smallest(first int, rest ...int) int {
+	for _, n := range rest {
+		if n < first {
+			first = n
+		}
+	}
+	return first
+}
+
+// fullRoster is everyone registered along with everyone in the league, in
+// name order.
+func fullRoster(registered []string, league League) []string {
+	book := nameBook{}
+	for _, name := range registered {
+		book.add(name)
+	}
+	for _, player := range league {
+		book.add(player.Name)
+	}
+
+	roster := make([]string, 0, len(book))
+	for _, name := range book {
+		roster = append(roster, name)
+	}
+	sort.Strings(roster)
+	return roster
+}
+
+// checkRegistration reports why name can't join a roster.
+func checkRegistration(roster []string, name string) error {
+	if strings.TrimSpace(name) == "" {
+		return ErrNoPlayerName
+	}
+	if contains(roster, name) {
+		return fmt.Errorf("%w: %s", ErrPlayerExists, name)
+	}
+	return nil
+}
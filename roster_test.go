@@ -0,0 +1,100 @@
+package poker_test
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+
+	poker "github.com/tsugoshi/learn-go-application"
+)
+
+func TestRosterPlayerStore(t *testing.T) {
+
+	newStore := func(t *testing.T) *poker.RosterPlayerStore {
+		t.Helper()
+		store := poker.NewInMemoryPlayerStore()
+		assertNoError(t, store.RecordWin("Cleo"))
+		assertNoError(t, store.RegisterPlayer("Chris"))
+		return poker.NewRosterPlayerStore(store)
+	}
+
+	t.Run("records wins for registered players and players in the league", func(t *testing.T) {
+		store := newStore(t)
+
+		assertNoError(t, store.RecordWin("Chris"))
+		assertNoError(t, store.RecordWin("Cleo"))
+
+		assertScoreEqual(t, playerWins(store, "Chris"), 1)
+		assertScoreEqual(t, playerWins(store, "Cleo"), 2)
+	})
+
+	t.Run("rejects a win for someone not on the roster", func(t *testing.T) {
+		store := newStore(t)
+
+		err := store.RecordWin("Chirs")
+
+		var notOnRoster *poker.NotOnRosterError
+		if !errors.As(err, &notOnRoster) {
+			t.Fatalf("got error %v, wanted a NotOnRosterError", err)
+		}
+		if want := []string{"Chris"}; !reflect.DeepEqual(notOnRoster.Suggestions, want) {
+			t.Errorf("got suggestions %v, wanted %v", notOnRoster.Suggestions, want)
+		}
+		if got, want := err.Error(), "Chirs is not on the roster, did you mean Chris?"; got != want {
+			t.Errorf("got error %q, wanted %q", got, want)
+		}
+		if player := store.GetLeague().Find("Chirs"); player != nil {
+			t.Errorf("expected Chirs not to be in the league, got %v", *player)
+		}
+	})
+
+	t.Run("rejects a game with anyone not on the roster", func(t *testing.T) {
+		store := newStore(t)
+
+		err := store.RecordGame(poker.GameResult{Winner: "Cleo", Players: []string{"Cleo", "Pepper"}})
+
+		if !errors.Is(err, poker.ErrNotOnRoster) {
+			t.Errorf("got error %v, wanted %v", err, poker.ErrNotOnRoster)
+		}
+		if games := store.GetGames(); len(games) != 1 {
+			t.Errorf("expected only the first game to be recorded, got %v", games)
+		}
+	})
+
+	t.Run("rejects starting a game with anyone not on the roster", func(t *testing.T) {
+		store := newStore(t)
+
+		_, err := store.StartGame(poker.GameResult{Players: []string{"Cleo", "Chirs"}})
+
+		if got, want := fmt.Sprint(err), "Chirs is not on the roster, did you mean Chris?"; got != want {
+			t.Errorf("got error %q, wanted %q", got, want)
+		}
+		if games := store.GetGames(); len(games) != 1 {
+			t.Errorf("expected only the first game to be recorded, got %v", games)
+		}
+	})
+}
+
+func TestSuggest(t *testing.T) {
+	roster := []string{"Chris", "Cleo", "Christina", "Pepper"}
+
+	cases := []struct {
+		name string
+		want []string
+	}{
+		{"Chirs", []string{"Chris"}},
+		{"chris", []string{"Chris"}},
+		{"Clea", []string{"Cleo"}},
+		{"Christine", []string{"Christina"}},
+		{"Apollo", []string{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := poker.Suggest(c.name, roster); !reflect.DeepEqual(got, c.want) {
+				t.Errorf("got suggestions %v, wanted %v", got, c.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package poker
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Season is a period of play, such as a quarter. Games recorded while a
+// season is open belong to it.
+type Season struct {
+	ID       string    `json:"id"`
+	OpenedAt time.Time `json:"openedAt"`
+	ClosedAt time.Time `json:"closedAt"`
+}
+
+var (
+	ErrNoActiveSeason = errors.New("there is no active season")
+	ErrSeasonExists   = errors.New("season already exists")
+	ErrUnknownSeason  = errors.New("no such season")
+)
+
+// Active reports whether the season is still open.
+func (s Season) Active() bool {
+	return s.ClosedAt.IsZero()
+}
+
+// QuarterSeasonID names the quarter t falls in, like "2026-Q3".
+func QuarterSeasonID(t time.Time) string {
+	return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())+2)/3)
+}
+
+// ForSeason returns the games played in the season with the given ID.
+func (h GameHistory) ForSeason(id string) GameHistory {
+	games := GameHistory{}
+	for _, game := range h {
+		if game.Season == id {
+			games = append(games, game)
+		}
+	}
+	return games
+}
+
+// activeSeason is the open season in seasons, if there is one.
+func activeSeason(seasons []Season) (Season, bool) {
+	for i := len(seasons) - 1; i >= 0; i-- {
+		if seasons[i].Active() {
+			return seasons[i], true
+		}
+	}
+	return Season{}, false
+}
+
+func findSeason(seasons []Season, id string) (Season, bool) {
+	for _, season := range seasons {
+		if season.ID == id {
+			return season, true
+		}
+	}
+	return Season{}, false
+}
+
+// startSeason closes any active season in seasons and opens a new one with
+// the given ID at time at.
+func startSeason(seasons []Season, id string, at time.Time) ([]Season, error) {
+	if id == "" {
+		return nil, errors.New("season needs an ID")
+	}
+	if _, ok := findSeason(seasons, id); ok {
+		return nil, fmt.Errorf("%w: %s", ErrSeasonExists, id)
+	}
+
+	seasons = closeSeason(seasons, at)
+	return append(seasons, Season{ID: id, OpenedAt: at}), nil
+}
+
+// closeSeason closes any active season in seasons at time at.
+func closeSeason(seasons []Season, at time.Time) []Season {
+	closed := append([]Season{}, seasons...)
+	for i := range closed {
+		if closed[i].Active() {
+			closed[i].ClosedAt = at
+		}
+	}
+	return closed
+}
@@ -1,20 +1,69 @@
 package poker
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"log"
+	"math"
 	"net/http"
+	"net/url"
 	"strconv"
+	"strings"
+	"sync"
 
 	"github.com/gorilla/websocket"
 )
 
+// PlayerStore keeps a league: its players, the games they played, its
+// seasons and its roster.
 type PlayerStore interface {
-	GetPlayerScore(name string) int
-	RecordWin(name string)
+	LeagueStore
+	GameStore
+	SeasonStore
+	RosterStore
+}
+
+// LeagueStore looks players up in the league.
+type LeagueStore interface {
+	// GetPlayer returns name's record, and whether they are a player at all:
+	// in the league or registered on the roster.
+	GetPlayer(name string) (Player, bool)
 	GetLeague() League
+	QueryLeague(query LeagueQuery) (LeaguePage, error)
+}
+
+// GameStore records the games played in a league.
+type GameStore interface {
+	RecordWin(name string) error
+	// StartGame records that game's players have started playing it, so it
+	// counts as a game played for each of them until RecordGame finishes it
+	// with a result of the same ID. It returns the game as recorded.
+	StartGame(game GameResult) (GameResult, error)
+	RecordGame(result GameResult) error
+	// ImportGames records every game, or none of them if any can't be. They
+	// stay in the season they name, or in none.
+	ImportGames(games GameHistory) error
+	GetGames() GameHistory
+}
+
+// SeasonStore partitions a league's games into seasons.
+type SeasonStore interface {
+	StartSeason(id string) error
+	CloseSeason() error
+	GetSeasons() []Season
+	GetSeasonLeague(id string) (League, error)
+}
+
+// RosterStore manages who is in a league.
+type RosterStore interface {
+	RenamePlayer(from, to string) error
+	MergePlayers(from, into string) error
+	DeletePlayer(name string) error
+	RegisterPlayer(name string) error
+	GetRoster() []string
 }
 
 type PlayerServer struct {
@@ -22,15 +71,44 @@ type PlayerServer struct {
 	http.Handler
 	template *template.Template
 	game     Game
+	leagues  leagues
+	session  session
+	blinds   BlindStructures
+	clock    Clock
 }
 
 type Player struct {
-	Name string
-	Wins int
+	Name        string
+	Wins        int
+	GamesPlayed int
+	Losses      int
+	// WinRate is the percentage of games played that were won.
+	WinRate float64
+	// Rating is the player's Elo rating, worked out from their games.
+	Rating float64
+}
+
+// NewPlayer makes a Player with the given record, working out their losses
+// and win rate. They are given the InitialRating.
+func NewPlayer(name string, wins, gamesPlayed int) Player {
+	player := Player{Name: name, Wins: wins, GamesPlayed: gamesPlayed, Rating: InitialRating}
+	player.updateRecord()
+	return player
+}
+
+func (p *Player) updateRecord() {
+	p.Losses = p.GamesPlayed - p.Wins
+	p.WinRate = 0
+	if p.GamesPlayed > 0 {
+		p.WinRate = math.Round(float64(p.Wins)/float64(p.GamesPlayed)*1000) / 10
+	}
 }
 
 type playerServerWS struct {
 	*websocket.Conn
+	// mu stops blind alerts and replies to clock commands being written at
+	// once, which websockets don't allow.
+	mu sync.Mutex
 }
 
 func newPlayerServerWS(w http.ResponseWriter, r *http.Request) *playerServerWS {
@@ -38,18 +116,22 @@ func newPlayerServerWS(w http.ResponseWriter, r *http.Request) *playerServerWS {
 	if err != nil {
 		log.Print("Error creating WebSocket", err)
 	}
-	return &playerServerWS{conn}
+	return &playerServerWS{Conn: conn}
 }
 
-func (p *playerServerWS) WaitForMessage() string {
+// WaitForMessage returns the next message, or an error once the connection
+// is closed.
+func (p *playerServerWS) WaitForMessage() (string, error) {
 	_, message, err := p.Conn.ReadMessage()
 	if err != nil {
-		log.Print("Error reading message from WebSocket", err)
+		return "", fmt.Errorf("problem reading message from WebSocket, %v", err)
 	}
-	return string(message)
+	return string(message), nil
 }
 
 func (w *playerServerWS) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 	err = w.WriteMessage(websocket.TextMessage, p)
 	if err != nil {
 		return 0, err
@@ -76,62 +158,640 @@ func NewPlayerServer(store PlayerStore, game Game) (*PlayerServer, error) {
 	p.template = tmpl
 	p.store = store
 	p.game = game
+	p.clock = RealClock
+	p.leagues.servers = map[string]*PlayerServer{}
+
+	router := p.routes()
+	router.Handle("/leagues", http.HandlerFunc(p.leaguesHandler))
+	router.Handle("/leagues/", http.HandlerFunc(p.namedLeagueHandler))
 
+	p.Handler = router
+
+	return p, nil
+}
+
+// routes are the routes served for each league.
+func (p *PlayerServer) routes() *http.ServeMux {
 	router := http.NewServeMux()
 	router.Handle("/league", http.HandlerFunc(p.leagueHandler))
+	router.Handle("/league.csv", http.HandlerFunc(p.leagueCSVHandler))
+	router.Handle("/games", http.HandlerFunc(p.gamesHandler))
+	router.Handle("/games.csv", http.HandlerFunc(p.gamesCSVHandler))
+	router.Handle("/export.json", http.HandlerFunc(p.exportHandler))
+	router.Handle("/ratings", http.HandlerFunc(p.ratingsHandler))
+	router.Handle("/seasons", http.HandlerFunc(p.seasonsHandler))
+	router.Handle("/seasons/close", http.HandlerFunc(p.closeSeasonHandler))
 	router.Handle("/players/", http.HandlerFunc(p.playerHandler))
 	router.Handle("/game", http.HandlerFunc(p.gameHandler))
 	router.Handle("/ws", http.HandlerFunc(p.webSocket))
-
-	p.Handler = router
-
-	return p, nil
+	router.Handle("/roster", http.HandlerFunc(p.rosterHandler))
+	router.Handle("/admin/players/rename", http.HandlerFunc(p.renamePlayerHandler))
+	router.Handle("/admin/players/merge", http.HandlerFunc(p.mergePlayersHandler))
+	router.Handle("/admin/players/delete", http.HandlerFunc(p.deletePlayerHandler))
+	router.Handle("/admin/import", http.HandlerFunc(p.importHandler))
+	router.Handle(APIVersion+"/", http.StripPrefix(APIVersion, p.apiRoutes()))
+	return router
 }
 
+// leagueHandler serves the all time league, or one season's with ?season=.
+// It is ordered by wins, or by ?sort=name, rating or winrate, in the ?order
+// asc or desc. Players are left out if they have played fewer than
+// ?minGames, or their names don't start with ?prefix. ?limit pages the
+// league, from ?offset or the ?cursor of the page before; the total and the
+// pages around are in the X-Total-Count and Link headers.
 func (p *PlayerServer) leagueHandler(w http.ResponseWriter, r *http.Request) {
+	page, status, err := p.queryLeague(w, r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
 
 	w.Header().Set("content-type", "application/json")
-	json.NewEncoder(w).Encode(p.store.GetLeague())
+	json.NewEncoder(w).Encode(page.Players)
 }
 
-func (p *PlayerServer) playerHandler(w http.ResponseWriter, r *http.Request) {
-	player := r.URL.Path[len("/players/"):]
+// queryLeague returns the page of the league asked for by the query
+// parameters taken by leagueHandler, setting the headers describing it, or
+// an error along with the status to report it with.
+func (p *PlayerServer) queryLeague(w http.ResponseWriter, query url.Values) (LeaguePage, int, error) {
+	q := LeagueQuery{
+		Season: query.Get("season"),
+		Sort:   query.Get("sort"),
+		Order:  query.Get("order"),
+		Prefix: query.Get("prefix"),
+		Cursor: query.Get("cursor"),
+	}
+	for _, param := range []struct {
+		name  string
+		value *int
+	}{{"minGames", &q.MinGames}, {"limit", &q.Limit}, {"offset", &q.Offset}} {
+		if v := query.Get(param.name); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return LeaguePage{}, http.StatusBadRequest, fmt.Errorf("%s must be a whole number, got %q", param.name, v)
+			}
+			*param.value = n
+		}
+	}
+
+	page, err := p.store.QueryLeague(q)
+	switch {
+	case errors.Is(err, ErrUnknownSeason):
+		return LeaguePage{}, http.StatusNotFound, err
+	case errors.Is(err, ErrBadLeagueQuery):
+		return LeaguePage{}, http.StatusBadRequest, err
+	case err != nil:
+		return LeaguePage{}, http.StatusInternalServerError, err
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(page.Total))
+	if links := pageLinks(query, q, page); len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+	return page, http.StatusOK, nil
+}
+
+// pageLinks link to the first, previous, next and last pages of the league
+// around page, relative to the request for it. The next page is found by
+// cursor unless page was found by offset, and there are only previous and
+// last pages to link to when it wasn't found by cursor.
+func pageLinks(query url.Values, q LeagueQuery, page LeaguePage) []string {
+	if q.Limit == 0 {
+		return nil
+	}
+
+	link := func(rel string, set func(url.Values)) string {
+		values := url.Values{}
+		for k, v := range query {
+			values[k] = v
+		}
+		values.Del("offset")
+		values.Del("cursor")
+		set(values)
+		return fmt.Sprintf(`<?%s>; rel="%s"`, values.Encode(), rel)
+	}
+	atOffset := func(offset int) func(url.Values) {
+		return func(values url.Values) {
+			if offset > 0 {
+				values.Set("offset", strconv.Itoa(offset))
+			}
+		}
+	}
+
+	byOffset := query.Get("offset") != ""
+	links := []string{link("first", atOffset(0))}
+	if byOffset && page.Offset > 0 {
+		prev := page.Offset - q.Limit
+		if prev < 0 {
+			prev = 0
+		}
+		links = append(links, link("prev", atOffset(prev)))
+	}
+	if page.NextCursor != "" {
+		if byOffset {
+			links = append(links, link("next", atOffset(page.Offset+len(page.Players))))
+		} else {
+			links = append(links, link("next", func(values url.Values) { values.Set("cursor", page.NextCursor) }))
+		}
+	}
+	if q.Cursor == "" && page.Total > 0 {
+		links = append(links, link("last", atOffset((page.Total-1)/q.Limit*q.Limit)))
+	}
+	return links
+}
+
+func (p *PlayerServer) ratingsHandler(w http.ResponseWriter, r *http.Request) {
 
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(p.store.GetLeague().Ratings())
+}
+
+// seasonsHandler lists the seasons, or starts a new one when a season like
+// {"id": "2026-Q3"} is posted to it. Starting a season closes the active one.
+func (p *PlayerServer) seasonsHandler(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("content-type", "application/json")
+		json.NewEncoder(w).Encode(p.store.GetSeasons())
 	case http.MethodPost:
-		p.processWin(w, player)
+		p.startSeason(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (p *PlayerServer) startSeason(w http.ResponseWriter, r *http.Request) {
+	var season Season
+	if err := json.NewDecoder(r.Body).Decode(&season); err != nil || season.ID == "" {
+		http.Error(w, `expected a season like {"id": "2026-Q3"}`, http.StatusBadRequest)
+		return
+	}
+
+	err := p.store.StartSeason(season.ID)
+	switch {
+	case errors.Is(err, ErrSeasonExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func (p *PlayerServer) closeSeasonHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	err := p.store.CloseSeason()
+	switch {
+	case errors.Is(err, ErrNoActiveSeason):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// rosterHandler lists the players on the roster, or registers one posted
+// like {"name": "Chris"}.
+func (p *PlayerServer) rosterHandler(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
 	case http.MethodGet:
-		p.showScore(w, player)
+		w.Header().Set("content-type", "application/json")
+		json.NewEncoder(w).Encode(p.store.GetRoster())
+	case http.MethodPost:
+		p.registerPlayer(w, r)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (p *PlayerServer) registerPlayer(w http.ResponseWriter, r *http.Request) {
+	var player struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&player); err != nil {
+		http.Error(w, `expected a player like {"name": "Chris"}`, http.StatusBadRequest)
+		return
+	}
+
+	err := p.store.RegisterPlayer(player.Name)
+	switch {
+	case errors.Is(err, ErrNoPlayerName):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, ErrPlayerExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+// renamePlayerHandler renames a player posted like {"from": "Chirs", "to": "Chris"}.
+func (p *PlayerServer) renamePlayerHandler(w http.ResponseWriter, r *http.Request) {
+	var rename struct {
+		From string `json:"from"`
+		To   string `json:"to"`
+	}
+	p.changePlayers(w, r, &rename, func() error {
+		return p.store.RenamePlayer(rename.From, rename.To)
+	})
+}
+
+// mergePlayersHandler adds one player's record to another's, posted like
+// {"from": "Chirs", "into": "Chris"}.
+func (p *PlayerServer) mergePlayersHandler(w http.ResponseWriter, r *http.Request) {
+	var merge struct {
+		From string `json:"from"`
+		Into string `json:"into"`
+	}
+	p.changePlayers(w, r, &merge, func() error {
+		return p.store.MergePlayers(merge.From, merge.Into)
+	})
+}
+
+// deletePlayerHandler deletes a player posted like {"name": "Chirs"}.
+func (p *PlayerServer) deletePlayerHandler(w http.ResponseWriter, r *http.Request) {
+	var player struct {
+		Name string `json:"name"`
+	}
+	p.changePlayers(w, r, &player, func() error {
+		return p.store.DeletePlayer(player.Name)
+	})
+}
+
+// changePlayers decodes the posted body into request and runs change.
+func (p *PlayerServer) changePlayers(w http.ResponseWriter, r *http.Request, request interface{}, change func() error) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(request); err != nil {
+		http.Error(w, fmt.Sprintf("problem parsing request, %v", err), http.StatusBadRequest)
+		return
+	}
+
+	err := change()
+	switch {
+	case errors.Is(err, ErrUnknownPlayer):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrPlayerExists), errors.Is(err, ErrPlayerHasWins):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, ErrBadRename):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (p *PlayerServer) gamesHandler(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(p.store.GetGames())
+}
+
+// leagueCSVHandler serves the league as CSV, taking the same query as
+// leagueHandler.
+func (p *PlayerServer) leagueCSVHandler(w http.ResponseWriter, r *http.Request) {
+	page, status, err := p.queryLeague(w, r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("content-type", csvContentType)
+	WriteLeagueCSV(w, page.Players)
+}
+
+// gamesCSVHandler serves the finished games as CSV, which can be imported
+// again.
+func (p *PlayerServer) gamesCSVHandler(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("content-type", csvContentType)
+	WriteGamesCSV(w, p.store.GetGames().Finished())
+}
+
+// exportHandler serves the league along with its games as JSON.
+func (p *PlayerServer) exportHandler(w http.ResponseWriter, r *http.Request) {
+
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(ExportLeague(p.store))
+}
+
+// importHandler imports a posted export, as CSV if it is sent as text/csv
+// and JSON otherwise, and serves the ImportReport. With ?dryRun=true it only
+// reports what would be imported.
+func (p *PlayerServer) importHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := JSONFormat
+	if strings.HasPrefix(r.Header.Get("content-type"), "text/csv") {
+		format = CSVFormat
+	}
+	export, err := ReadLeagueExport(r.Body, format)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dryRun, _ := strconv.ParseBool(r.URL.Query().Get("dryRun"))
+	report, err := ImportLeague(p.store, export, dryRun)
+	switch {
+	case errors.Is(err, ErrNotOnRoster):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrNoWinner):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	default:
+		w.Header().Set("content-type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	}
+}
+
+const csvContentType = "text/csv; charset=utf-8"
+
+// playerHandler serves /players/{name} and its sub-resources. Names are
+// path-escaped, so they can hold spaces or slashes.
+func (p *PlayerServer) playerHandler(w http.ResponseWriter, r *http.Request) {
+	player, resource, ok := parsePlayerPath(r.URL.EscapedPath(), "history", "stats")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	switch resource {
+	case "":
+		switch r.Method {
+		case http.MethodPost:
+			p.processWin(w, player)
+		case http.MethodGet:
+			p.showPlayer(w, r, player)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	case "history":
+		p.servePlayerResource(w, r, player, func(Player) interface{} {
+			return p.store.GetGames().ForPlayer(player)
+		})
+	case "stats":
+		p.servePlayerResource(w, r, player, func(record Player) interface{} {
+			return record
+		})
+	}
+}
+
+// parsePlayerPath splits an escaped /players/{name} or
+// /players/{name}/{resource} path, reporting whether it is one. Only the
+// given resources are allowed.
+func parsePlayerPath(path string, resources ...string) (name, resource string, ok bool) {
+	segments := strings.Split(strings.TrimPrefix(path, "/players/"), "/")
+	if len(segments) > 2 {
+		return "", "", false
+	}
+
+	name, err := url.PathUnescape(segments[0])
+	if err != nil || strings.TrimSpace(name) == "" {
+		return "", "", false
+	}
+
+	if len(segments) == 2 {
+		resource = segments[1]
+		if resource == "" || !containsResource(resources, resource) {
+			return "", "", false
+		}
+	}
+	return name, resource, true
+}
+
+func containsResource(resources []string, resource string) bool {
+	for _, r := range resources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// servePlayerResource serves what resource returns for the player's record
+// as JSON, or 404 if there is no such player.
+func (p *PlayerServer) servePlayerResource(w http.ResponseWriter, r *http.Request, player string, resource func(Player) interface{}) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", "GET")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	record, ok := p.store.GetPlayer(player)
+	if !ok {
+		http.Error(w, fmt.Sprintf("%v: %s", ErrUnknownPlayer, player), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("content-type", "application/json")
+	json.NewEncoder(w).Encode(resource(record))
+}
+
+// UseBlindStructures offers the blind structures to games started from the
+// game page or the API, in every league. Call it before serving.
+func (p *PlayerServer) UseBlindStructures(blinds BlindStructures) {
+	p.leagues.mu.Lock()
+	defer p.leagues.mu.Unlock()
+
+	p.blinds = blinds
+	for _, league := range p.leagues.servers {
+		league.blinds = blinds
+	}
+}
+
+// UseClock times live games with clock instead of the RealClock, in every
+// league. Call it before serving.
+func (p *PlayerServer) UseClock(clock Clock) {
+	p.leagues.mu.Lock()
+	defer p.leagues.mu.Unlock()
+
+	p.clock = clock
+	for _, league := range p.leagues.servers {
+		league.clock = clock
 	}
 }
 
 func (p *PlayerServer) gameHandler(w http.ResponseWriter, r *http.Request) {
 
-	p.template.Execute(w, nil)
+	p.template.Execute(w, struct{ Blinds []string }{p.blinds.Names()})
 }
 
+// webSocket plays a game over a websocket. Until the result is sent, clock
+// commands like pause and next adjust the clock, each answered with where it
+// is up to. If the connection drops first the game is abandoned, cancelling
+// its blind alerts.
 func (p *PlayerServer) webSocket(w http.ResponseWriter, r *http.Request) {
 
 	ws := newPlayerServerWS(w, r)
-	numberOfPlayersMessage := ws.WaitForMessage()
-	numberOfPlayers, _ := strconv.Atoi(string(numberOfPlayersMessage))
-	p.game.Start(numberOfPlayers, ws)
+	if ws.Conn == nil {
+		return
+	}
+	defer ws.Close()
+
+	message, err := ws.WaitForMessage()
+	if err != nil {
+		log.Print(err)
+		return
+	}
+	start := readStartMessage(message)
+	blinds, err := p.blinds.Find(start.Blinds)
+	if err != nil {
+		fmt.Fprint(ws, err)
+		return
+	}
 
-	message := ws.WaitForMessage()
-	p.game.Finish(string(message))
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	game, err := p.game.Start(ctx, start.NumberOfPlayers, blinds, ws, start.Players...)
+	if err != nil {
+		fmt.Fprint(ws, err)
+		return
+	}
+
+	for {
+		message, err = ws.WaitForMessage()
+		if err != nil {
+			log.Print("Abandoning game, ", err)
+			return
+		}
+		isCommand, err := runClockCommand(game, message)
+		if !isCommand {
+			break
+		}
+		if err != nil {
+			fmt.Fprint(ws, err)
+			continue
+		}
+		fmt.Fprint(ws, game.Status())
+	}
+	winner, runnersUp := extractFinishingOrder(message)
+	if err := game.Finish(winner, runnersUp...); err != nil {
+		log.Printf("error finishing game: %v", err)
+	}
 }
 
-func (p *PlayerServer) showScore(w http.ResponseWriter, player string) {
+// readStartMessage reads the message starting a game over the websocket,
+// either a NewSession or just the number of players or their names.
+func readStartMessage(message string) NewSession {
+	var start NewSession
+	if err := json.Unmarshal([]byte(message), &start); err == nil {
+		if len(start.Players) > 0 {
+			start.NumberOfPlayers = len(start.Players)
+		}
+		return start
+	}
+
+	start.NumberOfPlayers, start.Players, _ = extractPlayers(message)
+	return start
+}
+
+// showPlayer serves the player's record as JSON to clients that accept it,
+// and otherwise just their score as plain text.
+func (p *PlayerServer) showPlayer(w http.ResponseWriter, r *http.Request, player string) {
+	w.Header().Add("Vary", "Accept")
+
+	record, ok := p.store.GetPlayer(player)
+	if !ok {
+		http.Error(w, fmt.Sprintf("%v: %s", ErrUnknownPlayer, player), http.StatusNotFound)
+		return
+	}
 
-	score := p.store.GetPlayerScore(player)
-	if score == 0 {
-		w.WriteHeader(http.StatusNotFound)
+	if negotiate(r, "text/plain", "application/json") == "application/json" {
+		w.Header().Set("content-type", "application/json")
+		json.NewEncoder(w).Encode(record)
+		return
 	}
-	fmt.Fprint(w, score)
+
+	w.Header().Set("content-type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, record.Wins)
 }
 
 func (p *PlayerServer) processWin(w http.ResponseWriter, player string) {
-	p.store.RecordWin(player)
+	err := p.store.RecordWin(player)
+	switch {
+	case errors.Is(err, ErrNotOnRoster):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 	w.WriteHeader(http.StatusAccepted)
-	return
+}
+
+// negotiate picks whichever of the offered media types the request's Accept
+// header prefers, falling back to the first when it has no preference.
+func negotiate(r *http.Request, offers ...string) string {
+	best, bestQuality := offers[0], 0.0
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return best
+	}
+
+	for _, offer := range offers {
+		if q := acceptQuality(accept, offer); q > bestQuality {
+			best, bestQuality = offer, q
+		}
+	}
+	return best
+}
+
+// acceptQuality is how much an Accept header wants offer, from 0 to 1, going
+// by the most specific media range that matches it.
+func acceptQuality(accept, offer string) float64 {
+	quality, specificity := 0.0, 0
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+
+		s := rangeSpecificity(strings.TrimSpace(params[0]), offer)
+		if s <= specificity {
+			continue
+		}
+
+		quality, specificity = 1, s
+		for _, param := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+			if len(kv) == 2 && kv[0] == "q" {
+				if q, err := strconv.ParseFloat(kv[1], 64); err == nil {
+					quality = q
+				}
+			}
+		}
+	}
+	return quality
+}
+
+// rangeSpecificity is 3 if mediaRange is exactly offer, 2 if it is offer's
+// type/*, 1 if it is */* and 0 if it doesn't match.
+func rangeSpecificity(mediaRange, offer string) int {
+	switch {
+	case strings.EqualFold(mediaRange, offer):
+		return 3
+	case strings.EqualFold(mediaRange, strings.SplitN(offer, "/", 2)[0]+"/*"):
+		return 2
+	case mediaRange == "*/*":
+		return 1
+	default:
+		return 0
+	}
 }
@@ -3,6 +3,8 @@ package poker_test
 import (
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"sync"
 	"testing"
 
 	poker "github.com/tsugoshi/learn-go-application"
@@ -39,9 +41,43 @@ func TestRecordingWinsAndRetrievingThem(t *testing.T) {
 		got := poker.GetLeagueFromResponse(t, response.Body)
 
 		want := []poker.Player{
-			{player, 3},
+			poker.NewPlayer(player, 3, 3),
 		}
 
 		poker.AssertLeague(t, got, want)
 	})
 }
+
+func TestConcurrentWins(t *testing.T) {
+
+	database, cleanDatabase := createTempFile(t, `[]`)
+	defer cleanDatabase()
+
+	store, err := poker.NewFileSystemPlayerStore(database)
+	assertNoError(t, err)
+
+	server := poker.MustMakePlayerServer(t, store, &poker.GameSpy{})
+	player := "Pepper"
+	wantedWins := 200
+
+	var wg sync.WaitGroup
+	wg.Add(2 * wantedWins)
+	for i := 0; i < wantedWins; i++ {
+		go func() {
+			defer wg.Done()
+			server.ServeHTTP(httptest.NewRecorder(), poker.NewPostWinRequest(player))
+		}()
+		go func() {
+			defer wg.Done()
+			server.ServeHTTP(httptest.NewRecorder(), poker.NewGetLeagueRequest())
+		}()
+	}
+	wg.Wait()
+
+	response := httptest.NewRecorder()
+	server.ServeHTTP(response, poker.NewGetScoreRequest(player))
+	poker.AssertResponseBody(t, response.Body.String(), strconv.Itoa(wantedWins))
+
+	got := playerWins(reopenStore(t, database), player)
+	assertScoreEqual(t, got, wantedWins)
+}
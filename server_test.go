@@ -1,8 +1,11 @@
 package poker_test
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -14,12 +17,10 @@ import (
 func TestGETPlayers(t *testing.T) {
 
 	store := poker.StubPlayerStore{
-		map[string]int{
+		Scores: map[string]int{
 			"Pepper": 20,
 			"Floyd":  10,
 		},
-		nil,
-		nil,
 	}
 	server := poker.MustMakePlayerServer(t, &store, dummyGame)
 
@@ -62,11 +63,144 @@ func TestGETPlayers(t *testing.T) {
 	})
 }
 
+func TestPlayerResource(t *testing.T) {
+
+	store := poker.StubPlayerStore{
+		Scores: map[string]int{"Pepper": 20},
+		Roster: []string{"Newbie"},
+	}
+	server := poker.MustMakePlayerServer(t, &store, dummyGame)
+
+	t.Run("it returns 0 for a player who hasn't won yet", func(t *testing.T) {
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, poker.NewGetScoreRequest("Newbie"))
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		poker.AssertResponseBody(t, response.Body.String(), "0")
+		poker.AssertContentType(t, response, "text/plain; charset=utf-8")
+	})
+
+	t.Run("it returns the player as JSON when asked for it", func(t *testing.T) {
+		for _, accept := range []string{"application/json", "text/plain;q=0.5, application/json", "application/*"} {
+			request := poker.NewGetScoreRequest("Pepper")
+			request.Header.Set("Accept", accept)
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, request)
+
+			var got poker.Player
+			if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+				t.Fatalf("could not parse player for Accept %q, %v", accept, err)
+			}
+			poker.AssertContentType(t, response, poker.JsonContentType)
+			if want := poker.NewPlayer("Pepper", 20, 20); got != want {
+				t.Errorf("got player %+v, wanted %+v", got, want)
+			}
+		}
+	})
+
+	t.Run("it keeps returning the score to browsers", func(t *testing.T) {
+		request := poker.NewGetScoreRequest("Pepper")
+		request.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		poker.AssertResponseBody(t, response.Body.String(), "20")
+	})
+
+	t.Run("it returns 405 for other methods", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodDelete, "/players/Pepper", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusMethodNotAllowed)
+		if allow := response.Header().Get("Allow"); allow != "GET, POST" {
+			t.Errorf("got Allow %q, wanted %q", allow, "GET, POST")
+		}
+	})
+}
+
+func TestPlayerRoutes(t *testing.T) {
+
+	store := poker.StubPlayerStore{
+		Scores: map[string]int{"Mary Ann": 3, "AC/DC": 7},
+		League: []poker.Player{poker.NewPlayer("Mary Ann", 3, 4)},
+		Games: poker.GameHistory{
+			{ID: "1", Winner: "Mary Ann", Players: []string{"Mary Ann", "Cleo"}},
+			{ID: "2", Winner: "Cleo"},
+		},
+	}
+	server := poker.MustMakePlayerServer(t, &store, dummyGame)
+
+	t.Run("it decodes names with spaces and slashes", func(t *testing.T) {
+		for name, want := range map[string]string{"Mary Ann": "3", "AC/DC": "7"} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, poker.NewGetScoreRequest(name))
+
+			poker.AssertStatus(t, response.Code, http.StatusOK)
+			poker.AssertResponseBody(t, response.Body.String(), want)
+		}
+	})
+
+	t.Run("it returns 404 for malformed paths", func(t *testing.T) {
+		for _, path := range []string{"/players/", "/players/%20", "/players/Mary%20Ann/", "/players/Mary%20Ann/history/1", "/players/Mary%20Ann/height"} {
+			request, _ := http.NewRequest(http.MethodGet, path, nil)
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, request)
+
+			if response.Code != http.StatusNotFound {
+				t.Errorf("got status %d for %s, wanted %d", response.Code, path, http.StatusNotFound)
+			}
+		}
+	})
+
+	t.Run("it returns a player's game history", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/players/mary%20ann/history", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		var got poker.GameHistory
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("could not parse history %v", err)
+		}
+		poker.AssertContentType(t, response, poker.JsonContentType)
+		if len(got) != 1 || got[0].ID != "1" {
+			t.Errorf("expected just game 1, got %v", got)
+		}
+	})
+
+	t.Run("it returns a player's stats", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/players/Mary%20Ann/stats", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		var got poker.Player
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("could not parse stats %v", err)
+		}
+		if want := poker.NewPlayer("Mary Ann", 3, 4); got != want {
+			t.Errorf("got stats %+v, wanted %+v", got, want)
+		}
+	})
+
+	t.Run("it returns 404 for the stats of an unknown player", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/players/Apollo/stats", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusNotFound)
+	})
+}
+
 func TestStoreWins(t *testing.T) {
 	store := poker.StubPlayerStore{
-		map[string]int{},
-		nil,
-		nil,
+		Scores: map[string]int{},
 	}
 	server := poker.MustMakePlayerServer(t, &store, dummyGame)
 
@@ -90,12 +224,12 @@ func TestLeague(t *testing.T) {
 	t.Run("it returns the league table as JSON", func(t *testing.T) {
 
 		wantedLeague := []poker.Player{
-			{"Chris", 1},
-			{"Cornel", 2},
-			{"DiCaprio", 30},
+			{Name: "Chris", Wins: 1},
+			{Name: "Cornel", Wins: 2},
+			{Name: "DiCaprio", Wins: 30},
 		}
 
-		store := poker.StubPlayerStore{nil, nil, wantedLeague}
+		store := poker.StubPlayerStore{League: wantedLeague}
 		server := poker.MustMakePlayerServer(t, &store, dummyGame)
 
 		request := poker.NewGetLeagueRequest()
@@ -111,6 +245,628 @@ func TestLeague(t *testing.T) {
 	})
 }
 
+func TestLeagueByWinRate(t *testing.T) {
+
+	league := []poker.Player{
+		poker.NewPlayer("Chris", 4, 40),
+		poker.NewPlayer("Cleo", 3, 3),
+		poker.NewPlayer("Pepper", 1, 1),
+	}
+	store := poker.StubPlayerStore{League: league}
+	server := poker.MustMakePlayerServer(t, &store, dummyGame)
+
+	t.Run("it ranks by win rate leaving out players with too few games", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/league?sort=winrate&minGames=2", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		got := poker.GetLeagueFromResponse(t, response.Body)
+		want := []poker.Player{
+			poker.NewPlayer("Cleo", 3, 3),
+			poker.NewPlayer("Chris", 4, 40),
+		}
+		poker.AssertLeague(t, got, want)
+	})
+
+	t.Run("it rejects an unknown sort", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/league?sort=height", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("it rejects a bad minGames", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/league?sort=winrate&minGames=lots", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func TestLeaguePages(t *testing.T) {
+
+	league := []poker.Player{
+		poker.NewPlayer("Pepper", 4, 4),
+		poker.NewPlayer("Cleo", 3, 3),
+		poker.NewPlayer("Chris", 2, 2),
+		poker.NewPlayer("Ruth", 1, 1),
+		poker.NewPlayer("Floyd", 0, 1),
+	}
+	store := poker.StubPlayerStore{League: league}
+	server := poker.MustMakePlayerServer(t, &store, dummyGame)
+
+	t.Run("it serves a page of the league with links to the others", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/league?limit=2&offset=2", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		poker.AssertLeague(t, poker.GetLeagueFromResponse(t, response.Body), league[2:4])
+		assertHeader(t, response, "X-Total-Count", "5")
+		assertHeader(t, response, "Link", `<?limit=2>; rel="first", <?limit=2>; rel="prev", <?limit=2&offset=4>; rel="next", <?limit=2&offset=4>; rel="last"`)
+	})
+
+	t.Run("it follows cursors to the next page", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/league?sort=name&limit=3", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		poker.AssertLeague(t, poker.GetLeagueFromResponse(t, response.Body), []poker.Player{league[2], league[1], league[4]})
+		next := linkTo(t, response, "next")
+		if !strings.Contains(next, "cursor=") {
+			t.Fatalf("got next page %q, wanted one found by cursor", next)
+		}
+
+		request, _ = http.NewRequest(http.MethodGet, "/league"+next, nil)
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		poker.AssertLeague(t, poker.GetLeagueFromResponse(t, response.Body), []poker.Player{league[0], league[3]})
+	})
+
+	t.Run("it filters by name prefix", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/league?prefix=c&order=asc", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		poker.AssertLeague(t, poker.GetLeagueFromResponse(t, response.Body), []poker.Player{league[2], league[1]})
+		assertHeader(t, response, "X-Total-Count", "2")
+		assertHeader(t, response, "Link", "")
+	})
+
+	t.Run("it rejects bad pages", func(t *testing.T) {
+		for _, query := range []string{"limit=-1", "offset=lots", "order=sideways", "cursor=nobody"} {
+			request, _ := http.NewRequest(http.MethodGet, "/league?"+query, nil)
+			response := httptest.NewRecorder()
+
+			server.ServeHTTP(response, request)
+
+			poker.AssertStatus(t, response.Code, http.StatusBadRequest)
+		}
+	})
+}
+
+// linkTo returns the target of the response's Link to rel.
+func linkTo(t *testing.T, response *httptest.ResponseRecorder, rel string) string {
+	t.Helper()
+	for _, link := range strings.Split(response.Header().Get("Link"), ", ") {
+		if strings.HasSuffix(link, fmt.Sprintf(`; rel="%s"`, rel)) {
+			return strings.TrimPrefix(link[:strings.Index(link, ">")], "<")
+		}
+	}
+	t.Fatalf("no %s link in %q", rel, response.Header().Get("Link"))
+	return ""
+}
+
+func assertHeader(t *testing.T, response *httptest.ResponseRecorder, name, want string) {
+	t.Helper()
+	if got := response.Header().Get(name); got != want {
+		t.Errorf("got %s header %q, want %q", name, got, want)
+	}
+}
+
+func TestExportAndImport(t *testing.T) {
+
+	t.Run("it serves the league as CSV", func(t *testing.T) {
+		store := poker.StubPlayerStore{League: []poker.Player{poker.NewPlayer("Cleo", 3, 4), poker.NewPlayer("Chris", 1, 4)}}
+		server := poker.MustMakePlayerServer(t, &store, dummyGame)
+
+		request, _ := http.NewRequest(http.MethodGet, "/league.csv?limit=1", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		poker.AssertContentType(t, response, "text/csv; charset=utf-8")
+		poker.AssertResponseBody(t, response.Body.String(), "name,wins,games_played,losses,win_rate,rating\nCleo,3,4,1,75,1500\n")
+	})
+
+	t.Run("it imports posted CSV", func(t *testing.T) {
+		store := poker.StubPlayerStore{Scores: map[string]int{}}
+		server := poker.MustMakePlayerServer(t, &store, dummyGame)
+
+		request, _ := http.NewRequest(http.MethodPost, "/admin/import", strings.NewReader("id,winner,players\na1,Cleo,Cleo;Chris\n"))
+		request.Header.Set("Content-Type", "text/csv")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		var report poker.ImportReport
+		json.NewDecoder(response.Body).Decode(&report)
+		if report.Games != 1 || report.DryRun {
+			t.Errorf("got report %+v, want 1 game imported", report)
+		}
+		poker.AssertPlayerWin(t, &store, "Cleo")
+	})
+
+	t.Run("it only reports a dry run", func(t *testing.T) {
+		store := poker.StubPlayerStore{League: []poker.Player{poker.NewPlayer("Cleo", 3, 4)}}
+		server := poker.MustMakePlayerServer(t, &store, dummyGame)
+
+		request, _ := http.NewRequest(http.MethodPost, "/admin/import?dryRun=true", strings.NewReader(`{"league": [{"Name": "cleo", "Wins": 2}]}`))
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		var report poker.ImportReport
+		json.NewDecoder(response.Body).Decode(&report)
+		if !report.DryRun || report.Wins != 2 || len(report.Conflicts) != 1 || report.Conflicts[0].Existing.Name != "Cleo" {
+			t.Errorf("got report %+v, want a dry run of 2 wins conflicting with Cleo", report)
+		}
+		if len(store.WinCalls) != 0 {
+			t.Errorf("got wins %v recorded in a dry run", store.WinCalls)
+		}
+	})
+
+	t.Run("it rejects an import it can't read", func(t *testing.T) {
+		server := poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+		request, _ := http.NewRequest(http.MethodPost, "/admin/import", strings.NewReader("name,wins\nCleo,lots\n"))
+		request.Header.Set("Content-Type", "text/csv")
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusBadRequest)
+	})
+}
+
+func TestRatingsEndpoint(t *testing.T) {
+
+	ruth, cleo := poker.NewPlayer("Ruth", 1, 1), poker.NewPlayer("Cleo", 3, 4)
+	ruth.Rating, cleo.Rating = 1540, 1490
+	store := poker.StubPlayerStore{League: []poker.Player{cleo, ruth}}
+	server := poker.MustMakePlayerServer(t, &store, dummyGame)
+
+	t.Run("it returns everyone's rating, highest first", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/ratings", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		var got []poker.Rating
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("could not parse ratings %v", err)
+		}
+
+		poker.AssertContentType(t, response, poker.JsonContentType)
+		want := []poker.Rating{
+			{Name: "Ruth", Rating: 1540, GamesPlayed: 1},
+			{Name: "Cleo", Rating: 1490, GamesPlayed: 4},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v, wanted %v", got, want)
+		}
+	})
+
+	t.Run("it sorts the league by rating", func(t *testing.T) {
+		request, _ := http.NewRequest(http.MethodGet, "/league?sort=rating", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		got := poker.GetLeagueFromResponse(t, response.Body)
+		poker.AssertLeague(t, got, []poker.Player{ruth, cleo})
+	})
+}
+
+func TestSeasons(t *testing.T) {
+
+	t.Run("it returns a season's league", func(t *testing.T) {
+		wantedLeague := []poker.Player{poker.NewPlayer("Cleo", 2, 3)}
+		store := poker.StubPlayerStore{SeasonLeagues: map[string]poker.League{"2026-Q3": wantedLeague}}
+		server := poker.MustMakePlayerServer(t, &store, dummyGame)
+
+		request, _ := http.NewRequest(http.MethodGet, "/league?season=2026-Q3", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		poker.AssertLeague(t, poker.GetLeagueFromResponse(t, response.Body), wantedLeague)
+	})
+
+	t.Run("it returns 404 on an unknown season", func(t *testing.T) {
+		server := poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+		request, _ := http.NewRequest(http.MethodGet, "/league?season=1999-Q1", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("it starts and lists seasons", func(t *testing.T) {
+		store := poker.StubPlayerStore{}
+		server := poker.MustMakePlayerServer(t, &store, dummyGame)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newStartSeasonRequest(`{"id": "2026-Q3"}`))
+		poker.AssertStatus(t, response.Code, http.StatusCreated)
+
+		request, _ := http.NewRequest(http.MethodGet, "/seasons", nil)
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		var got []poker.Season
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("could not parse seasons %v", err)
+		}
+		poker.AssertContentType(t, response, poker.JsonContentType)
+		if len(got) != 1 || got[0].ID != "2026-Q3" || !got[0].Active() {
+			t.Errorf("expected the active season 2026-Q3, got %v", got)
+		}
+	})
+
+	t.Run("it refuses a season that already exists", func(t *testing.T) {
+		store := poker.StubPlayerStore{Seasons: []poker.Season{{ID: "2026-Q3"}}}
+		server := poker.MustMakePlayerServer(t, &store, dummyGame)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newStartSeasonRequest(`{"id": "2026-Q3"}`))
+
+		poker.AssertStatus(t, response.Code, http.StatusConflict)
+	})
+
+	t.Run("it refuses a season without an ID", func(t *testing.T) {
+		server := poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newStartSeasonRequest(`{}`))
+
+		poker.AssertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("it closes the active season", func(t *testing.T) {
+		store := poker.StubPlayerStore{Seasons: []poker.Season{{ID: "2026-Q3"}}}
+		server := poker.MustMakePlayerServer(t, &store, dummyGame)
+
+		request, _ := http.NewRequest(http.MethodPost, "/seasons/close", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusNoContent)
+		if store.ClosedSeasons != 1 {
+			t.Errorf("expected the season to be closed once, got %d", store.ClosedSeasons)
+		}
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusConflict)
+	})
+}
+
+func newStartSeasonRequest(body string) *http.Request {
+	request, _ := http.NewRequest(http.MethodPost, "/seasons", strings.NewReader(body))
+	return request
+}
+
+func TestLeagues(t *testing.T) {
+
+	newServer := func(t *testing.T) (*poker.PlayerServer, *poker.StubPlayerStore, map[string]*poker.StubPlayerStore) {
+		defaultStore := &poker.StubPlayerStore{}
+		server := poker.MustMakePlayerServer(t, defaultStore, dummyGame)
+
+		stores := map[string]*poker.StubPlayerStore{}
+		server.CreateLeaguesWith(func(id string) (poker.PlayerStore, poker.Game, error) {
+			stores[id] = &poker.StubPlayerStore{League: []poker.Player{poker.NewPlayer(id, 1, 1)}}
+			return stores[id], dummyGame, nil
+		})
+		return server, defaultStore, stores
+	}
+
+	t.Run("it creates and lists leagues", func(t *testing.T) {
+		server, _, _ := newServer(t)
+
+		for _, id := range []string{"tuesday", "friday"} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newCreateLeagueRequest(`{"id": "`+id+`"}`))
+			poker.AssertStatus(t, response.Code, http.StatusCreated)
+		}
+
+		request, _ := http.NewRequest(http.MethodGet, "/leagues", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		var got []string
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("could not parse leagues %v", err)
+		}
+		poker.AssertContentType(t, response, poker.JsonContentType)
+		if want := []string{"friday", "tuesday"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got leagues %v, wanted %v", got, want)
+		}
+	})
+
+	t.Run("each league is served from its own store", func(t *testing.T) {
+		server, defaultStore, stores := newServer(t)
+		server.ServeHTTP(httptest.NewRecorder(), newCreateLeagueRequest(`{"id": "tuesday"}`))
+
+		request, _ := http.NewRequest(http.MethodGet, "/leagues/tuesday/league", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		poker.AssertLeague(t, poker.GetLeagueFromResponse(t, response.Body), []poker.Player{poker.NewPlayer("tuesday", 1, 1)})
+
+		request, _ = http.NewRequest(http.MethodPost, "/leagues/tuesday/players/Pepper", nil)
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusAccepted)
+		poker.AssertPlayerWin(t, stores["tuesday"], "Pepper")
+		if len(defaultStore.WinCalls) != 0 {
+			t.Errorf("expected no wins in the default league, got %v", defaultStore.WinCalls)
+		}
+	})
+
+	t.Run("it serves leagues added up front", func(t *testing.T) {
+		server := poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+		store := &poker.StubPlayerStore{Scores: map[string]int{"Pepper": 20}}
+		if err := server.AddLeague("friday", store, dummyGame); err != nil {
+			t.Fatalf("didn't expect error adding league, got %v", err)
+		}
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest(t, "/leagues/friday/players/Pepper"))
+
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		poker.AssertResponseBody(t, response.Body.String(), "20")
+	})
+
+	t.Run("it returns 404 on an unknown league", func(t *testing.T) {
+		server, _, _ := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newLeagueRequest(t, "/leagues/sunday/league"))
+
+		poker.AssertStatus(t, response.Code, http.StatusNotFound)
+	})
+
+	t.Run("it refuses a league that already exists", func(t *testing.T) {
+		server, _, _ := newServer(t)
+		server.ServeHTTP(httptest.NewRecorder(), newCreateLeagueRequest(`{"id": "tuesday"}`))
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateLeagueRequest(`{"id": "tuesday"}`))
+
+		poker.AssertStatus(t, response.Code, http.StatusConflict)
+	})
+
+	t.Run("it refuses a bad league ID", func(t *testing.T) {
+		server, _, _ := newServer(t)
+
+		for _, body := range []string{`{"id": "../etc"}`, `{"id": ""}`, `tuesday`} {
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newCreateLeagueRequest(body))
+
+			poker.AssertStatus(t, response.Code, http.StatusBadRequest)
+		}
+	})
+
+	t.Run("it can't create leagues without a factory", func(t *testing.T) {
+		server := poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newCreateLeagueRequest(`{"id": "tuesday"}`))
+
+		poker.AssertStatus(t, response.Code, http.StatusMethodNotAllowed)
+	})
+}
+
+func newCreateLeagueRequest(body string) *http.Request {
+	request, _ := http.NewRequest(http.MethodPost, "/leagues", strings.NewReader(body))
+	return request
+}
+
+func newLeagueRequest(t *testing.T, path string) *http.Request {
+	t.Helper()
+	request, err := http.NewRequest(http.MethodGet, path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return request
+}
+
+func TestAdminPlayers(t *testing.T) {
+
+	newServer := func(t *testing.T) (*poker.PlayerServer, *poker.StubPlayerStore) {
+		store := &poker.StubPlayerStore{Scores: map[string]int{"Chirs": 1, "Chris": 20, "Cleo": 5, "Pepper": 0}}
+		return poker.MustMakePlayerServer(t, store, dummyGame), store
+	}
+
+	t.Run("it renames a player", func(t *testing.T) {
+		server, store := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newAdminRequest("rename", `{"from": "Cleo", "to": "Clea"}`))
+
+		poker.AssertStatus(t, response.Code, http.StatusNoContent)
+		if store.Scores["Clea"] != 5 {
+			t.Errorf("expected Clea to have Cleo's 5 wins, got %v", store.Scores)
+		}
+	})
+
+	t.Run("it merges players", func(t *testing.T) {
+		server, store := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newAdminRequest("merge", `{"from": "Chirs", "into": "Chris"}`))
+
+		poker.AssertStatus(t, response.Code, http.StatusNoContent)
+		if store.Scores["Chris"] != 21 {
+			t.Errorf("expected Chris to have 21 wins, got %v", store.Scores)
+		}
+	})
+
+	t.Run("it deletes a player", func(t *testing.T) {
+		server, store := newServer(t)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newAdminRequest("delete", `{"name": "Pepper"}`))
+
+		poker.AssertStatus(t, response.Code, http.StatusNoContent)
+		if _, ok := store.Scores["Pepper"]; ok {
+			t.Errorf("expected Pepper to be deleted, got %v", store.Scores)
+		}
+	})
+
+	cases := []struct {
+		name   string
+		action string
+		body   string
+		want   int
+	}{
+		{"unknown player", "delete", `{"name": "Apollo"}`, http.StatusNotFound},
+		{"deleting a player with wins", "delete", `{"name": "Chirs"}`, http.StatusConflict},
+		{"rename onto existing player", "rename", `{"from": "Chirs", "to": "Chris"}`, http.StatusConflict},
+		{"rename to nobody", "rename", `{"from": "Chirs"}`, http.StatusBadRequest},
+		{"bad body", "merge", `Chirs into Chris`, http.StatusBadRequest},
+	}
+	for _, c := range cases {
+		t.Run("it refuses "+c.name, func(t *testing.T) {
+			server, _ := newServer(t)
+
+			response := httptest.NewRecorder()
+			server.ServeHTTP(response, newAdminRequest(c.action, c.body))
+
+			poker.AssertStatus(t, response.Code, c.want)
+		})
+	}
+
+	t.Run("it only accepts POST", func(t *testing.T) {
+		server, _ := newServer(t)
+
+		request, _ := http.NewRequest(http.MethodGet, "/admin/players/delete", nil)
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		poker.AssertStatus(t, response.Code, http.StatusMethodNotAllowed)
+	})
+}
+
+func TestRoster(t *testing.T) {
+
+	t.Run("it registers and lists players", func(t *testing.T) {
+		store := poker.StubPlayerStore{}
+		server := poker.MustMakePlayerServer(t, &store, dummyGame)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRegisterRequest(`{"name": "Chris"}`))
+		poker.AssertStatus(t, response.Code, http.StatusCreated)
+
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, newRegisterRequest(`{"name": "Chris"}`))
+		poker.AssertStatus(t, response.Code, http.StatusConflict)
+
+		request, _ := http.NewRequest(http.MethodGet, "/roster", nil)
+		response = httptest.NewRecorder()
+		server.ServeHTTP(response, request)
+
+		var got []string
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("could not parse roster %v", err)
+		}
+		if want := []string{"Chris"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got roster %v, wanted %v", got, want)
+		}
+	})
+
+	t.Run("it refuses a player without a name", func(t *testing.T) {
+		server := poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, newRegisterRequest(`{"name": ""}`))
+
+		poker.AssertStatus(t, response.Code, http.StatusBadRequest)
+	})
+
+	t.Run("it returns 404 for a win by someone not on the roster", func(t *testing.T) {
+		store := poker.StubPlayerStore{Roster: []string{"Chris"}}
+		server := poker.MustMakePlayerServer(t, poker.NewRosterPlayerStore(&store), dummyGame)
+
+		response := httptest.NewRecorder()
+		server.ServeHTTP(response, poker.NewPostWinRequest("Chirs"))
+
+		poker.AssertStatus(t, response.Code, http.StatusNotFound)
+		if !strings.Contains(response.Body.String(), "did you mean Chris?") {
+			t.Errorf("expected a suggestion in %q", response.Body.String())
+		}
+		if len(store.WinCalls) != 0 {
+			t.Errorf("expected no wins to be recorded, got %v", store.WinCalls)
+		}
+	})
+}
+
+func newRegisterRequest(body string) *http.Request {
+	request, _ := http.NewRequest(http.MethodPost, "/roster", strings.NewReader(body))
+	return request
+}
+
+func newAdminRequest(action, body string) *http.Request {
+	request, _ := http.NewRequest(http.MethodPost, "/admin/players/"+action, strings.NewReader(body))
+	return request
+}
+
+func TestGames(t *testing.T) {
+
+	t.Run("it returns the game history as JSON", func(t *testing.T) {
+
+		wantedGames := poker.GameHistory{
+			{ID: "1", Winner: "Ruth", FinishingOrder: []string{"Ruth", "Cleo"}},
+			{ID: "2", Winner: "Cleo"},
+		}
+
+		store := poker.StubPlayerStore{Games: wantedGames}
+		server := poker.MustMakePlayerServer(t, &store, dummyGame)
+
+		request, _ := http.NewRequest(http.MethodGet, "/games", nil)
+		response := httptest.NewRecorder()
+
+		server.ServeHTTP(response, request)
+
+		var got poker.GameHistory
+		if err := json.NewDecoder(response.Body).Decode(&got); err != nil {
+			t.Fatalf("could not parse games %v", err)
+		}
+
+		poker.AssertContentType(t, response, poker.JsonContentType)
+		poker.AssertStatus(t, response.Code, http.StatusOK)
+		if !reflect.DeepEqual(got, wantedGames) {
+			t.Errorf("got %v, wanted %v", got, wantedGames)
+		}
+	})
+}
+
 func TestGame(t *testing.T) {
 	t.Run("GET /game returs 200", func(t *testing.T) {
 		server := poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, dummyGame)
@@ -125,7 +881,6 @@ func TestGame(t *testing.T) {
 	t.Run("start game with 3 players and declare Ruth as winner", func(t *testing.T) {
 		wantedBlindAlerter := "Blind is 100"
 		winner := "Ruth"
-		tenMS := 10 * time.Millisecond
 		game := &poker.GameSpy{BlindAlerter: []byte(wantedBlindAlerter)}
 
 		server := httptest.NewServer(poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, game))
@@ -139,8 +894,120 @@ func TestGame(t *testing.T) {
 		poker.AssertGameStartedWith(t, game, 3)
 		poker.AssertFinishCalledWith(t, game, winner)
 
-		within(t, tenMS, func() { assertWebSocketGotMessage(t, ws, wantedBlindAlerter) })
+		assertWebSocketGotMessage(t, ws, wantedBlindAlerter)
+
+	})
+
+	t.Run("it abandons the game when the websocket closes before a winner is declared", func(t *testing.T) {
+		game := &poker.GameSpy{}
+		server := httptest.NewServer(poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, game))
+		ws := poker.MustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer server.Close()
+
+		poker.WriteWSMessage(t, ws, "3")
+		poker.AssertGameStartedWith(t, game, 3)
+		ws.Close()
+
+		poker.AssertGameCancelled(t, game)
+		assertGameNotFinished(t, game)
+	})
+
+	t.Run("it adjusts the clock with commands sent before the winner", func(t *testing.T) {
+		clock := &poker.ClockSpy{Current: poker.ClockStatus{Level: 1, Levels: 3, Blinds: poker.BlindLevel{SmallBlind: 100, BigBlind: 200}, Paused: true}}
+		game := &poker.GameSpy{Clock: clock, BlindAlerter: []byte("Blind is 100")}
+		server := httptest.NewServer(poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, game))
+		ws := poker.MustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer server.Close()
+		defer ws.Close()
 
+		poker.WriteWSMessage(t, ws, "3")
+		assertWebSocketGotMessage(t, ws, "Blind is 100")
+		poker.WriteWSMessage(t, ws, "pause")
+		assertWebSocketGotMessage(t, ws, "level 1 of 3, blinds 100/200, 0s left, paused")
+		poker.WriteWSMessage(t, ws, "add 1m")
+		assertWebSocketGotMessage(t, ws, "level 1 of 3, blinds 100/200, 0s left, paused")
+		poker.WriteWSMessage(t, ws, "Ruth")
+
+		poker.AssertFinishCalledWith(t, game, "Ruth")
+		if got, want := clock.CommandsGiven(), []string{"pause", "add 1m0s"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got commands %v, wanted %v", got, want)
+		}
+	})
+
+	t.Run("it sends the blind alerts as the clock goes on", func(t *testing.T) {
+		clock := poker.NewFakeClock(time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC))
+		store := &poker.StubPlayerStore{}
+		game := poker.NewTexasHoldem(poker.AlerterOn(clock), store)
+		game.UseClock(clock)
+		turbo := poker.BlindStructure{Name: "turbo", Warning: poker.Duration(time.Minute), Levels: []poker.BlindLevel{
+			{SmallBlind: 25, BigBlind: 50, Duration: poker.Duration(3 * time.Minute)},
+			{SmallBlind: 50, BigBlind: 100},
+		}}
+
+		playerServer := poker.MustMakePlayerServer(t, store, game)
+		playerServer.UseBlindStructures(poker.BlindStructures{"turbo": turbo})
+		server := httptest.NewServer(playerServer)
+		ws := poker.MustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer server.Close()
+		defer ws.Close()
+
+		poker.WriteWSMessage(t, ws, `{"numberOfPlayers": 3, "blinds": "turbo"}`)
+		clock.WaitForTimers(3)
+
+		clock.Advance(0)
+		assertWebSocketGotMessage(t, ws, "blinds are now 25/50\n")
+		clock.Advance(2 * time.Minute)
+		assertWebSocketGotMessage(t, ws, "1 minute until blinds go to 50/100\n")
+		clock.Advance(time.Minute)
+		assertWebSocketGotMessage(t, ws, "blinds are now 50/100\n")
+
+		poker.WriteWSMessage(t, ws, "Ruth")
+		if _, _, err := ws.ReadMessage(); err == nil {
+			t.Fatal("expected the websocket to close once the game finished")
+		}
+		if games := store.WaitForGames(1); len(games) != 1 || games[0].FinalBlind != 50 {
+			t.Errorf("got games %+v, wanted one finishing on a blind of 50", games)
+		}
+	})
+
+	t.Run("start game with the blinds chosen on the game page", func(t *testing.T) {
+		turbo := poker.BlindStructure{Name: "turbo", Levels: []poker.BlindLevel{{SmallBlind: 25, BigBlind: 50}}}
+		game := &poker.GameSpy{}
+		playerServer := poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, game)
+		playerServer.UseBlindStructures(poker.BlindStructures{"turbo": turbo})
+
+		page := httptest.NewRecorder()
+		playerServer.ServeHTTP(page, poker.NewGetGameRequest())
+		if !strings.Contains(page.Body.String(), `<option value="turbo">`) {
+			t.Errorf("game page doesn't offer the turbo blinds, got %s", page.Body)
+		}
+
+		server := httptest.NewServer(playerServer)
+		ws := poker.MustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer server.Close()
+		defer ws.Close()
+
+		poker.WriteWSMessage(t, ws, `{"players": ["Ruth", "Cleo"], "blinds": "turbo"}`)
+		poker.WriteWSMessage(t, ws, "Ruth")
+
+		poker.AssertGameStartedWith(t, game, 2)
+		poker.AssertFinishCalledWith(t, game, "Ruth")
+		if !reflect.DeepEqual(game.StartedBlinds, turbo) {
+			t.Errorf("got blinds %v, wanted %v", game.StartedBlinds, turbo)
+		}
+	})
+
+	t.Run("it says so and doesn't start a game with unknown blinds", func(t *testing.T) {
+		game := &poker.GameSpy{}
+		server := httptest.NewServer(poker.MustMakePlayerServer(t, &poker.StubPlayerStore{}, game))
+		ws := poker.MustDialWS(t, "ws"+strings.TrimPrefix(server.URL, "http")+"/ws")
+		defer server.Close()
+		defer ws.Close()
+
+		poker.WriteWSMessage(t, ws, `{"numberOfPlayers": 3, "blinds": "glacial"}`)
+
+		assertWebSocketGotMessage(t, ws, "no such blind structure: glacial")
+		assertGameNotStarted(t, game)
 	})
 
 }
@@ -152,19 +1019,3 @@ func assertWebSocketGotMessage(t *testing.T, ws *websocket.Conn, wantedMessage s
 	}
 
 }
-
-func within(t testing.TB, d time.Duration, assert func()) {
-	t.Helper()
-
-	done := make(chan struct{}, 1)
-	go func() {
-		assert()
-		done <- struct{}{}
-	}()
-
-	select {
-	case <-time.After(d):
-		t.Error("Timed out")
-	case <-done:
-	}
-}
@@ -0,0 +1,89 @@
+package poker
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Kinds of PlayerStore understood by OpenPlayerStore.
+const (
+	FileStore     = "file"
+	EventLogStore = "eventlog"
+	BoltStore     = "bolt"
+	MemoryStore   = "memory"
+)
+
+// DefaultStorePath is the file each kind of store uses when no other is given.
+func DefaultStorePath(kind string) string {
+	switch kind {
+	case EventLogStore:
+		return "game.events.jsonl"
+	case BoltStore:
+		return "game.db"
+	default:
+		return "game.db.json"
+	}
+}
+
+// OpenPlayerStore opens the kind of store saved at path, returning a func to
+// close it. A MemoryStore ignores path and starts empty.
+func OpenPlayerStore(kind, path string) (PlayerStore, func(), error) {
+	switch kind {
+	case MemoryStore:
+		return NewInMemoryPlayerStore(), func() {}, nil
+	case FileStore:
+		store, closeFunc, err := FileSystemPlayerStoreFromFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, closeFunc, nil
+	case EventLogStore:
+		store, closeFunc, err := EventLogPlayerStoreFromFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, closeFunc, nil
+	case BoltStore:
+		store, closeFunc, err := BoltPlayerStoreFromFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, closeFunc, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown player store %q, want %q, %q, %q or %q", kind, FileStore, EventLogStore, BoltStore, MemoryStore)
+	}
+}
+
+// LeagueStorePath is where the league with the given ID keeps a kind of store
+// in dir, like leagues/tuesday.game.db.json.
+func LeagueStorePath(dir, kind, id string) string {
+	return filepath.Join(dir, id+"."+DefaultStorePath(kind))
+}
+
+// LeagueStoreIDs lists the leagues that have a kind of store in dir. A missing
+// dir has none.
+func LeagueStoreIDs(dir, kind string) ([]string, error) {
+	ids := []string{}
+	if kind == MemoryStore {
+		return ids, nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return ids, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("problem listing leagues in %s, %v", dir, err)
+	}
+
+	suffix := "." + DefaultStorePath(kind)
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), suffix)
+		if !entry.IsDir() && id != entry.Name() && leagueIDPattern.MatchString(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
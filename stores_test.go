@@ -0,0 +1,76 @@
+package poker_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	poker "github.com/tsugoshi/learn-go-application"
+)
+
+func TestPlayerStoreContracts(t *testing.T) {
+	for _, kind := range []string{poker.FileStore, poker.EventLogStore, poker.BoltStore} {
+		t.Run(kind, func(t *testing.T) {
+			poker.TestPlayerStoreContract(t, openedStoreFactory(kind))
+		})
+	}
+
+	t.Run(poker.MemoryStore, func(t *testing.T) {
+		poker.TestPlayerStoreContract(t, func(t *testing.T) (poker.PlayerStore, func() poker.PlayerStore) {
+			return poker.NewInMemoryPlayerStore(), nil
+		})
+	})
+}
+
+func TestOpenPlayerStore(t *testing.T) {
+	_, _, err := poker.OpenPlayerStore("postgres", filepath.Join(t.TempDir(), "db"))
+	if err == nil {
+		t.Error("expected an error opening an unknown kind of store but didn't get one")
+	}
+}
+
+func TestLeagueStoreIDs(t *testing.T) {
+	dir := t.TempDir()
+	for _, id := range []string{"tuesday", "friday"} {
+		store, closeStore, err := poker.OpenPlayerStore(poker.FileStore, poker.LeagueStorePath(dir, poker.FileStore, id))
+		assertNoError(t, err)
+		assertNoError(t, store.RecordWin("Pepper"))
+		closeStore()
+	}
+
+	got, err := poker.LeagueStoreIDs(dir, poker.FileStore)
+	assertNoError(t, err)
+	if want := []string{"friday", "tuesday"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got leagues %v, wanted %v", got, want)
+	}
+
+	got, err = poker.LeagueStoreIDs(filepath.Join(dir, "missing"), poker.FileStore)
+	assertNoError(t, err)
+	if len(got) != 0 {
+		t.Errorf("expected no leagues in a missing dir, got %v", got)
+	}
+}
+
+// openedStoreFactory opens stores of kind with poker.OpenPlayerStore, each in
+// its own temp dir.
+func openedStoreFactory(kind string) poker.PlayerStoreFactory {
+	return func(t *testing.T) (poker.PlayerStore, func() poker.PlayerStore) {
+		path := filepath.Join(t.TempDir(), poker.DefaultStorePath(kind))
+
+		open := func() (poker.PlayerStore, func()) {
+			store, closeStore, err := poker.OpenPlayerStore(kind, path)
+			assertNoError(t, err)
+			return store, closeStore
+		}
+
+		store, closeStore := open()
+		t.Cleanup(func() { closeStore() })
+
+		reopen := func() poker.PlayerStore {
+			closeStore()
+			store, closeStore = open()
+			return store
+		}
+		return store, reopen
+	}
+}
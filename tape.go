@@ -1,13 +1,98 @@
 package poker
 
-import "os"
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
 
+// Tape replaces the whole contents of the file at Path on every Write.
+//
+// The data is written to a temporary file next to Path, synced to disk and
+// renamed over the original, so a crash or a failed write leaves the previous
+// contents intact.
 type Tape struct {
-	File *os.File
+	Path string
+
+	// wrap decorates the temporary file before it is written to, letting
+	// tests inject failures part way through a write.
+	wrap func(io.Writer) io.Writer
 }
 
 func (t *Tape) Write(p []byte) (n int, err error) {
-	t.File.Truncate(0)
-	t.File.Seek(0, 0)
-	return t.File.Write(p)
+	dir := filepath.Dir(t.Path)
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(t.Path)+".tmp-*")
+	if err != nil {
+		return 0, fmt.Errorf("problem creating temp file for %s, %v", t.Path, err)
+	}
+
+	renamed := false
+	defer func() {
+		if !renamed {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	var w io.Writer = tmp
+	if t.wrap != nil {
+		w = t.wrap(tmp)
+	}
+
+	n, err = w.Write(p)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	if err != nil {
+		return n, fmt.Errorf("problem writing temp file for %s, %v", t.Path, err)
+	}
+
+	if err := copyFileMode(t.Path, tmp); err != nil {
+		return n, err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return n, fmt.Errorf("problem syncing temp file for %s, %v", t.Path, err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return n, fmt.Errorf("problem closing temp file for %s, %v", t.Path, err)
+	}
+
+	if err := os.Rename(tmp.Name(), t.Path); err != nil {
+		return n, fmt.Errorf("problem replacing %s, %v", t.Path, err)
+	}
+	renamed = true
+
+	return n, syncDir(dir)
+}
+
+func copyFileMode(path string, to *os.File) error {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("problem getting fileInfo from file %s, %v", path, err)
+	}
+
+	if err := to.Chmod(info.Mode().Perm()); err != nil {
+		return fmt.Errorf("problem setting mode of temp file for %s, %v", path, err)
+	}
+	return nil
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("problem opening directory %s, %v", dir, err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("problem syncing directory %s, %v", dir, err)
+	}
+	return nil
 }
@@ -12,12 +12,11 @@ func TestTape_Write(t *testing.T) {
 	file, clean := createTempFile(t, "12345")
 	defer clean()
 
-	tape := &poker.Tape{file}
-	tape.Write([]byte("abc"))
+	tape := &poker.Tape{Path: file.Name()}
+	_, err := tape.Write([]byte("abc"))
+	assertNoError(t, err)
 
-	file.Seek(0, 0)
-
-	newFileContents, _ := ioutil.ReadAll(file)
+	newFileContents, _ := ioutil.ReadFile(file.Name())
 	got := string(newFileContents)
 	want := "abc"
 	if got != want {
@@ -1,11 +1,15 @@
 package poker
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
+	"sync"
 	"testing"
 	"time"
 
@@ -13,58 +17,389 @@ import (
 )
 
 type StubPlayerStore struct {
-	Scores   map[string]int
-	WinCalls []string
-	League   League
+	Scores        map[string]int
+	WinCalls      []string
+	League        League
+	Games         GameHistory
+	Started       GameHistory
+	Seasons       []Season
+	SeasonLeagues map[string]League
+	ClosedSeasons int
+	Roster        []string
+
+	// mu guards the games, which a server may record on its own goroutine.
+	mu sync.Mutex
+	// gamesRecorded is broadcast as each game is recorded, for
+	// WaitForGames.
+	gamesRecorded *sync.Cond
 }
 
-func (s *StubPlayerStore) GetPlayerScore(name string) int {
-	score := s.Scores[name]
-	return score
+func (s *StubPlayerStore) GetPlayer(name string) (Player, bool) {
+	if player := s.League.Find(name); player != nil {
+		return *player, true
+	}
+	if score, ok := s.Scores[name]; ok {
+		return NewPlayer(name, score, score), true
+	}
+	if contains(s.Roster, name) {
+		return NewPlayer(name, 0, 0), true
+	}
+	return Player{}, false
 }
 
-func (s *StubPlayerStore) RecordWin(name string) {
+func (s *StubPlayerStore) RecordWin(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.WinCalls = append(s.WinCalls, name)
+	return nil
 }
 
 func (s *StubPlayerStore) GetLeague() League {
 	return s.League
 }
 
+func (s *StubPlayerStore) RecordGame(result GameResult) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.recordGame(result)
+	return nil
+}
+
+func (s *StubPlayerStore) StartGame(game GameResult) (GameResult, error) {
+	game, err := startedGame(game)
+	if err != nil {
+		return game, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Started = append(s.Started, game)
+	return game, nil
+}
+
+func (s *StubPlayerStore) ImportGames(games GameHistory) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, game := range games {
+		s.recordGame(game)
+	}
+	return nil
+}
+
+// recordGame records result, waking anyone waiting for it. It must be
+// called with mu held.
+func (s *StubPlayerStore) recordGame(result GameResult) {
+	s.Games = append(s.Games, result)
+	s.WinCalls = append(s.WinCalls, result.Winner)
+	s.recorded().Broadcast()
+}
+
+// recorded is gamesRecorded, made the first time it is needed. It must be
+// called with mu held.
+func (s *StubPlayerStore) recorded() *sync.Cond {
+	if s.gamesRecorded == nil {
+		s.gamesRecorded = sync.NewCond(&s.mu)
+	}
+	return s.gamesRecorded
+}
+
+// WaitForGames waits until at least n games have been recorded, however
+// long that takes, and returns them.
+func (s *StubPlayerStore) WaitForGames(n int) GameHistory {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.Games) < n {
+		s.recorded().Wait()
+	}
+	return append(GameHistory{}, s.Games...)
+}
+
+func (s *StubPlayerStore) GetGames() GameHistory {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Games
+}
+
+func (s *StubPlayerStore) StartSeason(id string) error {
+	seasons, err := startSeason(s.Seasons, id, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	s.Seasons = seasons
+	return nil
+}
+
+func (s *StubPlayerStore) CloseSeason() error {
+	if _, ok := activeSeason(s.Seasons); !ok {
+		return ErrNoActiveSeason
+	}
+	s.Seasons = closeSeason(s.Seasons, time.Now().UTC())
+	s.ClosedSeasons++
+	return nil
+}
+
+func (s *StubPlayerStore) GetSeasons() []Season {
+	return s.Seasons
+}
+
+func (s *StubPlayerStore) GetSeasonLeague(id string) (League, error) {
+	league, ok := s.SeasonLeagues[id]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSeason, id)
+	}
+	return league, nil
+}
+
+// QueryLeague answers query from League, or SeasonLeagues, taking them to be
+// sorted by wins already.
+func (s *StubPlayerStore) QueryLeague(query LeagueQuery) (LeaguePage, error) {
+	league := s.League
+	if query.Season != "" {
+		var err error
+		if league, err = s.GetSeasonLeague(query.Season); err != nil {
+			return LeaguePage{}, err
+		}
+	}
+	return newLeagueIndex(league).query(query)
+}
+
+func (s *StubPlayerStore) RenamePlayer(from, to string) error {
+	if err := checkRename(s.scoresLeague(), from, to, false); err != nil {
+		return err
+	}
+	s.Scores[to] = s.Scores[from]
+	delete(s.Scores, from)
+	return nil
+}
+
+func (s *StubPlayerStore) MergePlayers(from, into string) error {
+	if err := checkRename(s.scoresLeague(), from, into, true); err != nil {
+		return err
+	}
+	s.Scores[into] += s.Scores[from]
+	delete(s.Scores, from)
+	return nil
+}
+
+func (s *StubPlayerStore) DeletePlayer(name string) error {
+	if err := checkDelete(s.scoresLeague(), name); err != nil {
+		return err
+	}
+	delete(s.Scores, name)
+	return nil
+}
+
+func (s *StubPlayerStore) RegisterPlayer(name string) error {
+	if err := checkRegistration(s.Roster, name); err != nil {
+		return err
+	}
+	s.Roster = append(s.Roster, name)
+	return nil
+}
+
+func (s *StubPlayerStore) GetRoster() []string {
+	return s.Roster
+}
+
+// scoresLeague is a league with a player for each of the stub's Scores.
+func (s *StubPlayerStore) scoresLeague() League {
+	league := League{}
+	for name, score := range s.Scores {
+		league = append(league, NewPlayer(name, score, score))
+	}
+	return league
+}
+
 type GameSpy struct {
 	StartedWith    int
+	StartedContext context.Context
+	StartedBlinds  BlindStructure
+	StartedPlayers []string
 	StartCalled    bool
 	BlindAlerter   []byte
 	FinishedWith   string
+	FinishedOrder  []string
 	FinishedCalled bool
+	FinishError    error
+	StartError     error
+	Clock          *ClockSpy
+
+	mu sync.Mutex
+	// changed is broadcast when Start or Finish is called, for anyone
+	// waiting on them.
+	changed *sync.Cond
+}
+
+// called records a call to Start or Finish, waking anyone waiting for one.
+func (g *GameSpy) called(record func()) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	record()
+	g.calls().Broadcast()
+}
+
+// calls is changed, made the first time it is needed. It must be called
+// with mu held.
+func (g *GameSpy) calls() *sync.Cond {
+	if g.changed == nil {
+		g.changed = sync.NewCond(&g.mu)
+	}
+	return g.changed
+}
+
+// waitFor waits for Start or Finish to be called until happened reports it
+// has, however long that takes.
+func (g *GameSpy) waitFor(happened func() bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for !happened() {
+		g.calls().Wait()
+	}
+}
+
+func (g *GameSpy) Start(ctx context.Context, numberOfPlayers int, blinds BlindStructure, out io.Writer, players ...string) (Tournament, error) {
+	if g.StartError == nil {
+		out.Write(g.BlindAlerter)
+	}
+
+	var clock *ClockSpy
+	g.called(func() {
+		g.StartedContext = ctx
+		g.StartedWith = numberOfPlayers
+		g.StartedBlinds = blinds
+		g.StartedPlayers = players
+		g.StartCalled = true
+		if g.Clock == nil {
+			g.Clock = &ClockSpy{}
+		}
+		clock = g.Clock
+	})
+	if g.StartError != nil {
+		return nil, g.StartError
+	}
+	return tournamentSpy{ClockSpy: clock, game: g}, nil
+}
+
+// tournamentSpy is a game started by a GameSpy, which records it finishing.
+type tournamentSpy struct {
+	*ClockSpy
+	game *GameSpy
+}
+
+func (t tournamentSpy) Finish(winner string, runnersUp ...string) error {
+	return t.game.finish(winner, runnersUp...)
+}
+
+// ClockSpy records the commands a TournamentClock is given, failing them
+// with Err if it is set.
+type ClockSpy struct {
+	mu       sync.Mutex
+	Commands []string
+	Err      error
+	Current  ClockStatus
 }
 
-func (g *GameSpy) Start(numberOfPlayers int, out io.Writer) {
-	g.StartedWith = numberOfPlayers
-	g.StartCalled = true
-	out.Write(g.BlindAlerter)
+func (c *ClockSpy) Pause() error {
+	return c.command("pause")
 }
 
-func (g *GameSpy) Finish(winner string) {
-	g.FinishedWith = winner
-	g.FinishedCalled = true
+func (c *ClockSpy) Resume() error {
+	return c.command("resume")
 }
 
+func (c *ClockSpy) Next() error {
+	return c.command("next")
+}
+
+func (c *ClockSpy) Back() error {
+	return c.command("back")
+}
+
+func (c *ClockSpy) AddTime(d time.Duration) error {
+	return c.command(fmt.Sprintf("add %v", d))
+}
+
+func (c *ClockSpy) Status() ClockStatus {
+	return c.Current
+}
+
+func (c *ClockSpy) command(command string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Commands = append(c.Commands, command)
+	return c.Err
+}
+
+// CommandsGiven are the commands given to the clock so far.
+func (c *ClockSpy) CommandsGiven() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.Commands...)
+}
+
+func (g *GameSpy) finish(winner string, runnersUp ...string) error {
+	g.called(func() {
+		g.FinishedWith = winner
+		g.FinishedOrder = append([]string{winner}, runnersUp...)
+		g.FinishedCalled = true
+	})
+	return g.FinishError
+}
+
+// ScheduledAlert is an alert a SpyBlindAlerter was asked to schedule.
+// Amount is the small blind of its Level.
 type ScheduledAlert struct {
 	At     time.Duration
 	Amount int
+	Level  BlindLevel
+	Kind   AlertKind
 }
 
 type SpyBlindAlerter struct {
-	alerts []ScheduledAlert
+	alerts   []ScheduledAlert
+	contexts []context.Context
 }
 
 func (s ScheduledAlert) String() string {
-	return fmt.Sprintf("%d amount chips at %v", s.Amount, s.At)
+	switch s.Kind {
+	case BlindsWarning:
+		return fmt.Sprintf("warning of %d amount chips at %v", s.Amount, s.At)
+	case BreakStart:
+		return fmt.Sprintf("break before %d amount chips at %v", s.Amount, s.At)
+	default:
+		return fmt.Sprintf("%d amount chips at %v", s.Amount, s.At)
+	}
+}
+
+// Alerts are the alerts scheduled so far, in order.
+func (s *SpyBlindAlerter) Alerts() []ScheduledAlert {
+	return s.alerts
+}
+
+func (s *SpyBlindAlerter) ScheduleAlertAt(ctx context.Context, duration time.Duration, alert Alert, to io.Writer) {
+	s.alerts = append(s.alerts, ScheduledAlert{At: duration, Amount: alert.Level.SmallBlind, Level: alert.Level, Kind: alert.Kind})
+	s.contexts = append(s.contexts, ctx)
 }
 
-func (s *SpyBlindAlerter) ScheduleAlertAt(duration time.Duration, amount int, to io.Writer) {
-	s.alerts = append(s.alerts, ScheduledAlert{duration, amount})
+// Pending are the alerts scheduled that haven't been cancelled, in order.
+func (s *SpyBlindAlerter) Pending() []ScheduledAlert {
+	var pending []ScheduledAlert
+	for i, ctx := range s.contexts {
+		if ctx.Err() == nil {
+			pending = append(pending, s.alerts[i])
+		}
+	}
+	return pending
+}
+
+// Cancelled reports whether every alert scheduled so far has been cancelled.
+func (s *SpyBlindAlerter) Cancelled() bool {
+	for _, ctx := range s.contexts {
+		if ctx.Err() == nil {
+			return false
+		}
+	}
+	return len(s.contexts) > 0
 }
 
 func AssertLeague(t *testing.T, got, want []Player) {
@@ -91,12 +426,12 @@ func AssertContentType(t *testing.T, response *httptest.ResponseRecorder, want s
 }
 
 func NewGetScoreRequest(name string) *http.Request {
-	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/players/%s", name), nil)
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("/players/%s", url.PathEscape(name)), nil)
 	return req
 }
 
 func NewPostWinRequest(name string) *http.Request {
-	request, _ := http.NewRequest(http.MethodPost, "/players/Pepper", nil)
+	request, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("/players/%s", url.PathEscape(name)), nil)
 	return request
 }
 func NewGetLeagueRequest() *http.Request {
@@ -160,6 +495,10 @@ func AssertScheduledAlert(t *testing.T, got, want ScheduledAlert) {
 	if got.At != want.At {
 		t.Errorf("expected scheduled at %v, got %v", want.At, got.At)
 	}
+
+	if got.Kind != want.Kind {
+		t.Errorf("expected %v, got %v", want, got)
+	}
 }
 
 func MustMakePlayerServer(t *testing.T, store PlayerStore, game Game) *PlayerServer {
@@ -187,35 +526,888 @@ func WriteWSMessage(t *testing.T, conn *websocket.Conn, message string) {
 	}
 }
 
+// AssertFinishCalledWith waits for the game to be finished, then checks who
+// won.
 func AssertFinishCalledWith(t testing.TB, game *GameSpy, winner string) {
 	t.Helper()
-	passed := retryUntil(500*time.Millisecond, func() bool {
-		return game.FinishedWith == winner
+	var got string
+	game.waitFor(func() bool {
+		got = game.FinishedWith
+		return game.FinishedCalled
 	})
 
-	if !passed {
-		t.Errorf("expected finish called with %q, but got %q", winner, game.FinishedWith)
+	if got != winner {
+		t.Errorf("expected finish called with %q, but got %q", winner, got)
 	}
 }
 
+// AssertGameCancelled waits for the game to start, then for the context it
+// was started with to be done, cancelling its alerts.
+func AssertGameCancelled(t testing.TB, game *GameSpy) {
+	t.Helper()
+	var ctx context.Context
+	game.waitFor(func() bool {
+		ctx = game.StartedContext
+		return game.StartCalled
+	})
+
+	<-ctx.Done()
+}
+
+// AssertGameStartedWith waits for the game to start, then checks how many
+// players it was started with.
 func AssertGameStartedWith(t *testing.T, game *GameSpy, numberOfPlayersWanted int) {
 	t.Helper()
+	var got int
+	game.waitFor(func() bool {
+		got = game.StartedWith
+		return game.StartCalled
+	})
+
+	if got != numberOfPlayersWanted {
+		t.Errorf("wanted Start called with %d but got %d", numberOfPlayersWanted, got)
+	}
+}
+
+// FakeClock is a Clock that only moves on when advanced, running the
+// functions due as it goes.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+	// added counts the functions added, and is broadcast as each is for
+	// WaitForTimers.
+	added      *sync.Cond
+	addedSoFar int
+}
+
+type fakeTimer struct {
+	clock   *FakeClock
+	at      time.Time
+	f       func()
+	waiting bool
+}
+
+// NewFakeClock makes a FakeClock showing now.
+func NewFakeClock(now time.Time) *FakeClock {
+	c := &FakeClock{now: now}
+	c.added = sync.NewCond(&c.mu)
+	return c
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc runs f once the clock is advanced by d, even if d is 0.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := &fakeTimer{clock: c, at: c.now.Add(d), f: f, waiting: true}
+	c.timers = append(c.timers, timer)
+	c.addedSoFar++
+	c.added.Broadcast()
+	return timer
+}
+
+// WaitForTimers blocks until at least n functions have been added since the
+// clock was made, for when they are added by another goroutine.
+func (c *FakeClock) WaitForTimers(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.addedSoFar < n {
+		c.added.Wait()
+	}
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	waiting := t.waiting
+	t.waiting = false
+	return waiting
+}
+
+// Advance moves the clock on by d, running each function due by then in the
+// order they are due, with the clock showing the time each was due at.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		var next *fakeTimer
+		for _, timer := range c.timers {
+			if timer.waiting && !timer.at.After(end) && (next == nil || timer.at.Before(next.at)) {
+				next = timer
+			}
+		}
+		if next == nil {
+			c.now = end
+			c.forgetStopped()
+			c.mu.Unlock()
+			return
+		}
+		if next.at.After(c.now) {
+			c.now = next.at
+		}
+		next.waiting = false
+		c.mu.Unlock()
+
+		next.f()
+	}
+}
+
+// forgetStopped drops the timers that have run or been stopped. It must be
+// called with mu held.
+func (c *FakeClock) forgetStopped() {
+	waiting := c.timers[:0]
+	for _, timer := range c.timers {
+		if timer.waiting {
+			waiting = append(waiting, timer)
+		}
+	}
+	c.timers = waiting
+}
+
+// FailingWriter passes through the first After bytes to W and then fails,
+// simulating a disk that fills up part way through a write.
+type FailingWriter struct {
+	W     io.Writer
+	After int
+}
+
+var ErrWriteFailed = errors.New("simulated write failure")
+
+func (f *FailingWriter) Write(p []byte) (int, error) {
+	if len(p) <= f.After {
+		n, err := f.W.Write(p)
+		f.After -= n
+		return n, err
+	}
+
+	n, _ := f.W.Write(p[:f.After])
+	f.After = 0
+	return n, ErrWriteFailed
+}
+
+// FailWritesAfter makes every future save of store fail once n bytes of it have been written.
+func FailWritesAfter(store *FileSystemPlayerStore, n int) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	store.tape.wrap = func(w io.Writer) io.Writer {
+		return &FailingWriter{W: w, After: n}
+	}
+}
+
+// PlayerStoreFactory returns a new, empty store along with a func that closes
+// it and opens it again from wherever it saved its data. Stores that don't
+// persist anything return a nil reopen.
+type PlayerStoreFactory func(t *testing.T) (store PlayerStore, reopen func() PlayerStore)
+
+// TestPlayerStoreContract checks the behaviour every PlayerStore must have.
+func TestPlayerStoreContract(t *testing.T, factory PlayerStoreFactory) {
+	t.Run("unknown players have no wins", func(t *testing.T) {
+		store, _ := factory(t)
+
+		assertContractScore(t, store, "Apollo", 0)
+		if player := store.GetLeague().Find("Apollo"); player != nil {
+			t.Errorf("expected Apollo not to be in the league, got %v", *player)
+		}
+	})
+
+	t.Run("records wins for new and existing players", func(t *testing.T) {
+		store, _ := factory(t)
+
+		mustRecordWins(t, store, "Pepper", 1)
+		assertContractScore(t, store, "Pepper", 1)
+
+		mustRecordWins(t, store, "Pepper", 2)
+		assertContractScore(t, store, "Pepper", 3)
+	})
+
+	t.Run("league is ordered by wins, most first", func(t *testing.T) {
+		store, _ := factory(t)
+
+		mustRecordWins(t, store, "Cleo", 1)
+		mustRecordWins(t, store, "Chris", 3)
+		mustRecordWins(t, store, "Pepper", 2)
+
+		AssertLeague(t, store.GetLeague(), []Player{
+			NewPlayer("Chris", 3, 3),
+			NewPlayer("Pepper", 2, 2),
+			NewPlayer("Cleo", 1, 1),
+		})
+	})
+
+	t.Run("players on the same wins are in name order", func(t *testing.T) {
+		store, _ := factory(t)
+
+		mustRecordWins(t, store, "Pepper", 1)
+		mustRecordWins(t, store, "Cleo", 1)
+		mustRecordWins(t, store, "Chris", 1)
+
+		AssertLeague(t, store.GetLeague(), []Player{
+			NewPlayer("Chris", 1, 1),
+			NewPlayer("Cleo", 1, 1),
+			NewPlayer("Pepper", 1, 1),
+		})
+	})
+
+	t.Run("league is a copy", func(t *testing.T) {
+		store, _ := factory(t)
+		mustRecordWins(t, store, "Cleo", 1)
+
+		league := store.GetLeague()
+		league[0].Wins = 100
+
+		assertContractScore(t, store, "Cleo", 1)
+	})
+
+	t.Run("concurrent wins are all recorded", func(t *testing.T) {
+		store, _ := factory(t)
+		wantedWins := 50
+
+		var wg sync.WaitGroup
+		wg.Add(2 * wantedWins)
+		for i := 0; i < wantedWins; i++ {
+			go func() {
+				defer wg.Done()
+				if err := store.RecordWin("Pepper"); err != nil {
+					t.Errorf("didn't expect error recording win, got %v", err)
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				store.GetLeague()
+			}()
+		}
+		wg.Wait()
+
+		assertContractScore(t, store, "Pepper", wantedWins)
+	})
+
+	t.Run("records games and counts their winners", func(t *testing.T) {
+		store, _ := factory(t)
+
+		startedAt := time.Date(2026, 7, 1, 19, 0, 0, 0, time.UTC)
+		game := GameResult{
+			StartedAt:       startedAt,
+			FinishedAt:      startedAt.Add(2 * time.Hour),
+			NumberOfPlayers: 3,
+			Players:         []string{"Ruth", "Cleo", "Chris"},
+			FinishingOrder:  []string{"Ruth", "Cleo", "Chris"},
+			Winner:          "Ruth",
+			FinalBlind:      800,
+		}
+		if err := store.RecordGame(game); err != nil {
+			t.Fatalf("didn't expect error recording game, got %v", err)
+		}
+		mustRecordWins(t, store, "Cleo", 1)
+
+		games := store.GetGames()
+		if len(games) != 2 {
+			t.Fatalf("expected 2 games, got %v", games)
+		}
+		AssertGameResult(t, games[0], game)
+		if games[1].Winner != "Cleo" || games[1].FinishedAt.IsZero() {
+			t.Errorf("expected a finished game won by Cleo, got %+v", games[1])
+		}
+
+		assertContractScore(t, store, "Ruth", 1)
+		AssertLeague(t, store.GetLeague(), games.League().Sorted())
+	})
+
+	t.Run("records games played and losses for everyone who took part", func(t *testing.T) {
+		store, _ := factory(t)
+
+		game := GameResult{Players: []string{"Ruth", "Cleo", "Chris"}, Winner: "Ruth"}
+		if err := store.RecordGame(game); err != nil {
+			t.Fatalf("didn't expect error recording game, got %v", err)
+		}
+		game.Winner = "Cleo"
+		if err := store.RecordGame(game); err != nil {
+			t.Fatalf("didn't expect error recording game, got %v", err)
+		}
+
+		want := []Player{
+			NewPlayer("Cleo", 1, 2),
+			NewPlayer("Ruth", 1, 2),
+			NewPlayer("Chris", 0, 2),
+		}
+		want[0].Rating, want[1].Rating, want[2].Rating = 1508.55, 1506.9, 1484.55
+
+		AssertLeague(t, store.GetLeague(), want)
+		AssertLeague(t, store.GetGames().League().Sorted(), want)
+	})
+
+	t.Run("refuses a game without a winner", func(t *testing.T) {
+		store, _ := factory(t)
+
+		if err := store.RecordGame(GameResult{NumberOfPlayers: 3}); err == nil {
+			t.Error("expected an error recording a game without a winner but didn't get one")
+		}
+		if games := store.GetGames(); len(games) != 0 {
+			t.Errorf("expected no games, got %v", games)
+		}
+	})
+
+	t.Run("counts a started game as played until its result replaces it", func(t *testing.T) {
+		store, reopen := factory(t)
+
+		mustStartSeason(t, store, "2026-Q3")
+		started, err := store.StartGame(GameResult{NumberOfPlayers: 2, Players: []string{"Ruth", "Cleo"}})
+		if err != nil {
+			t.Fatalf("didn't expect error starting game, got %v", err)
+		}
+		AssertLeague(t, store.GetLeague(), []Player{NewPlayer("Cleo", 0, 1), NewPlayer("Ruth", 0, 1)})
+
+		if reopen != nil {
+			store = reopen()
+		}
+		mustStartSeason(t, store, "2026-Q4")
+		if err := store.RecordGame(GameResult{ID: started.ID, Players: started.Players, Winner: "Cleo"}); err != nil {
+			t.Fatalf("didn't expect error recording game, got %v", err)
+		}
 
-	passed := retryUntil(500*time.Millisecond, func() bool {
-		return game.StartedWith == numberOfPlayersWanted
+		games := store.GetGames()
+		if len(games) != 1 || games[0].Winner != "Cleo" || games[0].Season != "2026-Q3" {
+			t.Errorf("got games %+v, wanted only Cleo's win in 2026-Q3", games)
+		}
+		want := []Player{NewPlayer("Cleo", 1, 1), NewPlayer("Ruth", 0, 1)}
+		want[0].Rating, want[1].Rating = 1516, 1484
+		AssertLeague(t, store.GetLeague(), want)
+		AssertLeague(t, games.League().Sorted(), want)
+	})
+
+	t.Run("refuses to start a game without players", func(t *testing.T) {
+		store, _ := factory(t)
+
+		if _, err := store.StartGame(GameResult{NumberOfPlayers: 3}); !errors.Is(err, ErrNoPlayers) {
+			t.Errorf("got error %v starting a game without players, wanted %v", err, ErrNoPlayers)
+		}
+		if games := store.GetGames(); len(games) != 0 {
+			t.Errorf("expected no games, got %v", games)
+		}
 	})
 
-	if !passed {
-		t.Errorf("wanted Start called with %d but got %d", numberOfPlayersWanted, game.StartedWith)
+	t.Run("imports games in the seasons they name", func(t *testing.T) {
+		store, reopen := factory(t)
+		mustStartSeason(t, store, "2026-Q4")
+		mustRecordWins(t, store, "Cleo", 1)
+
+		games := GameHistory{
+			{Players: []string{"cleo", "Ruth"}, Winner: "Ruth", Season: "2025-Q1"},
+			{Players: []string{"Ruth", "Chris"}, Winner: "Chris"},
+		}
+		if err := store.ImportGames(games); err != nil {
+			t.Fatalf("didn't expect error importing games, got %v", err)
+		}
+		if reopen != nil {
+			store = reopen()
+		}
+
+		got := store.GetGames()
+		if len(got) != 3 || got[1].Season != "2025-Q1" || got[2].Season != "" {
+			t.Fatalf("expected the games in seasons 2025-Q1 and none, got %+v", got)
+		}
+		if got[1].Players[0] != "Cleo" {
+			t.Errorf("expected cleo to be shown as Cleo, got %v", got[1].Players)
+		}
+		AssertLeague(t, store.GetLeague(), got.League().Sorted())
+	})
+
+	t.Run("imports no games if any can't be recorded", func(t *testing.T) {
+		store, _ := factory(t)
+
+		games := GameHistory{{Winner: "Ruth"}, {Players: []string{"Ruth", "Chris"}}}
+		if err := store.ImportGames(games); !errors.Is(err, ErrNoWinner) {
+			t.Errorf("got error %v importing a game without a winner, wanted %v", err, ErrNoWinner)
+		}
+		if games := store.GetGames(); len(games) != 0 {
+			t.Errorf("expected no games, got %v", games)
+		}
+	})
+
+	t.Run("games are recorded in the active season", func(t *testing.T) {
+		store, _ := factory(t)
+
+		mustRecordWins(t, store, "Chris", 1)
+		mustStartSeason(t, store, "2026-Q3")
+		mustRecordWins(t, store, "Cleo", 2)
+
+		if season := store.GetGames()[1].Season; season != "2026-Q3" {
+			t.Errorf("expected game to be in season 2026-Q3, got %q", season)
+		}
+
+		league, err := store.GetSeasonLeague("2026-Q3")
+		if err != nil {
+			t.Fatalf("didn't expect error getting season league, got %v", err)
+		}
+		AssertLeague(t, league, []Player{NewPlayer("Cleo", 2, 2)})
+
+		AssertLeague(t, store.GetLeague(), []Player{
+			NewPlayer("Cleo", 2, 2),
+			NewPlayer("Chris", 1, 1),
+		})
+	})
+
+	t.Run("starting a season closes the active one", func(t *testing.T) {
+		store, _ := factory(t)
+
+		mustStartSeason(t, store, "2026-Q3")
+		mustRecordWins(t, store, "Cleo", 1)
+		mustStartSeason(t, store, "2026-Q4")
+		mustRecordWins(t, store, "Chris", 1)
+
+		seasons := store.GetSeasons()
+		if len(seasons) != 2 || seasons[0].ID != "2026-Q3" || seasons[1].ID != "2026-Q4" {
+			t.Fatalf("expected seasons 2026-Q3 and 2026-Q4, got %v", seasons)
+		}
+		if seasons[0].Active() || !seasons[1].Active() {
+			t.Errorf("expected only 2026-Q4 to be active, got %v", seasons)
+		}
+
+		league, err := store.GetSeasonLeague("2026-Q3")
+		if err != nil {
+			t.Fatalf("didn't expect error getting season league, got %v", err)
+		}
+		AssertLeague(t, league, []Player{NewPlayer("Cleo", 1, 1)})
+	})
+
+	t.Run("games after closing a season are in no season", func(t *testing.T) {
+		store, _ := factory(t)
+
+		mustStartSeason(t, store, "2026-Q3")
+		if err := store.CloseSeason(); err != nil {
+			t.Fatalf("didn't expect error closing season, got %v", err)
+		}
+		mustRecordWins(t, store, "Cleo", 1)
+
+		if season := store.GetGames()[0].Season; season != "" {
+			t.Errorf("expected game to be in no season, got %q", season)
+		}
+		if err := store.CloseSeason(); !errors.Is(err, ErrNoActiveSeason) {
+			t.Errorf("got error %v closing with no active season, wanted %v", err, ErrNoActiveSeason)
+		}
+	})
+
+	t.Run("refuses a season that already exists", func(t *testing.T) {
+		store, _ := factory(t)
+
+		mustStartSeason(t, store, "2026-Q3")
+		if err := store.StartSeason("2026-Q3"); !errors.Is(err, ErrSeasonExists) {
+			t.Errorf("got error %v starting 2026-Q3 again, wanted %v", err, ErrSeasonExists)
+		}
+		if err := store.StartSeason(""); err == nil {
+			t.Error("expected an error starting a season without an ID but didn't get one")
+		}
+		if seasons := store.GetSeasons(); len(seasons) != 1 || !seasons[0].Active() {
+			t.Errorf("expected just the active 2026-Q3 season, got %v", seasons)
+		}
+	})
+
+	t.Run("unknown seasons have no league", func(t *testing.T) {
+		store, _ := factory(t)
+
+		if _, err := store.GetSeasonLeague("1999-Q1"); !errors.Is(err, ErrUnknownSeason) {
+			t.Errorf("got error %v for an unknown season, wanted %v", err, ErrUnknownSeason)
+		}
+	})
+
+	t.Run("renames a player in every game they played", func(t *testing.T) {
+		store, _ := factory(t)
+
+		mustRecordWins(t, store, "Chirs", 2)
+		if err := store.RecordGame(GameResult{Players: []string{"Cleo", "Chirs"}, Winner: "Cleo"}); err != nil {
+			t.Fatalf("didn't expect error recording game, got %v", err)
+		}
+
+		if err := store.RenamePlayer("Chirs", "Chris"); err != nil {
+			t.Fatalf("didn't expect error renaming player, got %v", err)
+		}
+
+		assertContractScore(t, store, "Chirs", 0)
+		assertContractScore(t, store, "Chris", 2)
+		if got := store.GetGames()[2].Players; !reflect.DeepEqual(got, []string{"Cleo", "Chris"}) {
+			t.Errorf("got players %v after renaming, wanted Cleo and Chris", got)
+		}
+		AssertLeague(t, store.GetLeague(), store.GetGames().League().Sorted())
+	})
+
+	t.Run("refuses to rename onto an existing or unknown player", func(t *testing.T) {
+		store, _ := factory(t)
+		mustRecordWins(t, store, "Chirs", 1)
+		mustRecordWins(t, store, "Chris", 1)
+
+		if err := store.RenamePlayer("Chirs", "Chris"); !errors.Is(err, ErrPlayerExists) {
+			t.Errorf("got error %v renaming onto Chris, wanted %v", err, ErrPlayerExists)
+		}
+		if err := store.RenamePlayer("Apollo", "Pepper"); !errors.Is(err, ErrUnknownPlayer) {
+			t.Errorf("got error %v renaming Apollo, wanted %v", err, ErrUnknownPlayer)
+		}
+		if err := store.RenamePlayer("Chirs", ""); !errors.Is(err, ErrBadRename) {
+			t.Errorf("got error %v renaming to nobody, wanted %v", err, ErrBadRename)
+		}
+		assertContractScore(t, store, "Chirs", 1)
+	})
+
+	t.Run("merges one player's record into another's", func(t *testing.T) {
+		store, _ := factory(t)
+
+		mustRecordWins(t, store, "Chirs", 2)
+		mustRecordWins(t, store, "Chris", 3)
+		if err := store.RecordGame(GameResult{Players: []string{"Chris", "Chirs", "Cleo"}, Winner: "Cleo"}); err != nil {
+			t.Fatalf("didn't expect error recording game, got %v", err)
+		}
+
+		if err := store.MergePlayers("Chirs", "Chris"); err != nil {
+			t.Fatalf("didn't expect error merging players, got %v", err)
+		}
+
+		assertContractScore(t, store, "Chris", 5)
+		if player := store.GetLeague().Find("Chirs"); player != nil {
+			t.Errorf("expected Chirs to be merged away, got %v", *player)
+		}
+		if got := store.GetLeague().Find("Chris").GamesPlayed; got != 6 {
+			t.Errorf("expected Chris to have played 6 games, got %d", got)
+		}
+		if err := store.MergePlayers("Chris", "Apollo"); !errors.Is(err, ErrUnknownPlayer) {
+			t.Errorf("got error %v merging into Apollo, wanted %v", err, ErrUnknownPlayer)
+		}
+	})
+
+	t.Run("deletes a player from the games they played", func(t *testing.T) {
+		store, _ := factory(t)
+
+		if err := store.RecordGame(GameResult{Players: []string{"Cleo", "Chirs", "Ruth"}, Winner: "Cleo"}); err != nil {
+			t.Fatalf("didn't expect error recording game, got %v", err)
+		}
+
+		if err := store.DeletePlayer("Chirs"); err != nil {
+			t.Fatalf("didn't expect error deleting player, got %v", err)
+		}
+
+		games := store.GetGames()
+		if len(games) != 1 || !reflect.DeepEqual(games[0].Participants(), []string{"Cleo", "Ruth"}) {
+			t.Errorf("expected Cleo's game without Chirs, got %v", games)
+		}
+		if player := store.GetLeague().Find("Ruth"); player == nil || player.GamesPlayed != 1 {
+			t.Errorf("expected Ruth to keep the game she played, got %v", player)
+		}
+
+		if err := store.DeletePlayer("Chirs"); !errors.Is(err, ErrUnknownPlayer) {
+			t.Errorf("got error %v deleting Chirs again, wanted %v", err, ErrUnknownPlayer)
+		}
+	})
+
+	t.Run("refuses to delete a player who has won games", func(t *testing.T) {
+		store, _ := factory(t)
+
+		if err := store.RecordGame(GameResult{Players: []string{"Cleo", "Chirs"}, Winner: "Chirs"}); err != nil {
+			t.Fatalf("didn't expect error recording game, got %v", err)
+		}
+
+		if err := store.DeletePlayer("Chirs"); !errors.Is(err, ErrPlayerHasWins) {
+			t.Errorf("got error %v deleting Chirs, wanted %v", err, ErrPlayerHasWins)
+		}
+		if games := store.GetGames(); len(games) != 1 {
+			t.Errorf("expected the game Chirs won to be kept, got %v", games)
+		}
+	})
+
+	t.Run("names differing in case or spacing are the same player", func(t *testing.T) {
+		store, _ := factory(t)
+
+		mustRecordWins(t, store, " Chris", 1)
+		mustRecordWins(t, store, "chris", 1)
+		mustRecordWins(t, store, "CHRIS  ", 1)
+
+		assertContractScore(t, store, "cHrIs", 3)
+		AssertLeague(t, store.GetLeague(), []Player{NewPlayer("Chris", 3, 3)})
+		for _, game := range store.GetGames() {
+			if game.Winner != "Chris" {
+				t.Errorf("expected game to be recorded as won by Chris, got %+v", game)
+			}
+		}
+
+		if err := store.RegisterPlayer("CHRIS"); !errors.Is(err, ErrPlayerExists) {
+			t.Errorf("got error %v registering CHRIS, wanted %v", err, ErrPlayerExists)
+		}
+	})
+
+	t.Run("players are shown by the name they registered with", func(t *testing.T) {
+		store, _ := factory(t)
+
+		if err := store.RegisterPlayer("Ruth"); err != nil {
+			t.Fatalf("didn't expect error registering player, got %v", err)
+		}
+		mustRecordWins(t, store, "ruth", 1)
+
+		AssertLeague(t, store.GetLeague(), []Player{NewPlayer("Ruth", 1, 1)})
+	})
+
+	t.Run("renaming can change how a name is shown", func(t *testing.T) {
+		store, _ := factory(t)
+		mustRecordWins(t, store, "chris", 2)
+
+		if err := store.RenamePlayer("CHRIS", "Chris"); err != nil {
+			t.Fatalf("didn't expect error renaming player, got %v", err)
+		}
+
+		AssertLeague(t, store.GetLeague(), []Player{NewPlayer("Chris", 2, 2)})
+		if err := store.MergePlayers("Chris", "chris"); !errors.Is(err, ErrBadRename) {
+			t.Errorf("got error %v merging Chris with themselves, wanted %v", err, ErrBadRename)
+		}
+	})
+
+	t.Run("reports whether a player exists", func(t *testing.T) {
+		store, _ := factory(t)
+		mustRecordWins(t, store, "Cleo", 1)
+		if err := store.RegisterPlayer("Chris"); err != nil {
+			t.Fatalf("didn't expect error registering player, got %v", err)
+		}
+
+		cases := map[string]bool{"cleo": true, "Chris": true, "Apollo": false}
+		for name, want := range cases {
+			if _, got := store.GetPlayer(name); got != want {
+				t.Errorf("got %v for whether %s exists, wanted %v", got, name, want)
+			}
+		}
+
+		if player, _ := store.GetPlayer("Chris"); player != NewPlayer("Chris", 0, 0) {
+			t.Errorf("got %+v for Chris, wanted a player with no games", player)
+		}
+		if player, _ := store.GetPlayer("cleo"); player != NewPlayer("Cleo", 1, 1) {
+			t.Errorf("got %+v for Cleo, wanted their record", player)
+		}
+	})
+
+	t.Run("registers players on the roster", func(t *testing.T) {
+		store, _ := factory(t)
+
+		mustRecordWins(t, store, "Cleo", 1)
+		if err := store.RegisterPlayer("Chris"); err != nil {
+			t.Fatalf("didn't expect error registering player, got %v", err)
+		}
+
+		if got, want := store.GetRoster(), []string{"Chris", "Cleo"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got roster %v, wanted %v", got, want)
+		}
+		if player := store.GetLeague().Find("Chris"); player != nil {
+			t.Errorf("expected Chris not to be in the league until they play, got %v", *player)
+		}
+
+		for _, name := range []string{"Chris", "Cleo"} {
+			if err := store.RegisterPlayer(name); !errors.Is(err, ErrPlayerExists) {
+				t.Errorf("got error %v registering %s again, wanted %v", err, name, ErrPlayerExists)
+			}
+		}
+		if err := store.RegisterPlayer(" "); !errors.Is(err, ErrNoPlayerName) {
+			t.Errorf("got error %v registering no name, wanted %v", err, ErrNoPlayerName)
+		}
+	})
+
+	t.Run("queries pages of the league", func(t *testing.T) {
+		store, _ := factory(t)
+		mustRecordWins(t, store, "Pepper", 4)
+		mustRecordWins(t, store, "Cleo", 3)
+		mustRecordWins(t, store, "Chris", 2)
+		mustRecordWins(t, store, "Ruth", 1)
+
+		page := mustQueryLeague(t, store, LeagueQuery{Limit: 2, Offset: 1})
+		AssertLeague(t, page.Players, []Player{NewPlayer("Cleo", 3, 3), NewPlayer("Chris", 2, 2)})
+		if page.Total != 4 || page.Offset != 1 || page.NextCursor == "" {
+			t.Errorf("got total %d, offset %d and cursor %q, wanted 4, 1 and a cursor", page.Total, page.Offset, page.NextCursor)
+		}
+
+		page = mustQueryLeague(t, store, LeagueQuery{Limit: 2, Cursor: page.NextCursor})
+		AssertLeague(t, page.Players, []Player{NewPlayer("Ruth", 1, 1)})
+		if page.Offset != 3 || page.NextCursor != "" {
+			t.Errorf("got offset %d and cursor %q for the last page, wanted 3 and none", page.Offset, page.NextCursor)
+		}
+	})
+
+	t.Run("queries the league by name and prefix", func(t *testing.T) {
+		store, _ := factory(t)
+		for i, name := range []string{"pepper", "Cleo", "Chris", "Ruth"} {
+			mustRecordWins(t, store, name, i+1)
+		}
+
+		page := mustQueryLeague(t, store, LeagueQuery{Sort: "name", Prefix: "c"})
+		AssertLeague(t, page.Players, []Player{NewPlayer("Chris", 3, 3), NewPlayer("Cleo", 2, 2)})
+
+		page = mustQueryLeague(t, store, LeagueQuery{Sort: "name", Order: "desc", Limit: 2})
+		AssertLeague(t, page.Players, []Player{NewPlayer("Ruth", 4, 4), NewPlayer("pepper", 1, 1)})
+
+		page = mustQueryLeague(t, store, LeagueQuery{Order: "asc", Prefix: " P"})
+		AssertLeague(t, page.Players, []Player{NewPlayer("pepper", 1, 1)})
+	})
+
+	t.Run("queries see new wins", func(t *testing.T) {
+		store, _ := factory(t)
+		mustRecordWins(t, store, "Cleo", 1)
+		mustQueryLeague(t, store, LeagueQuery{Sort: "name"})
+		mustRecordWins(t, store, "Chris", 1)
+
+		page := mustQueryLeague(t, store, LeagueQuery{Sort: "name"})
+		AssertLeague(t, page.Players, []Player{NewPlayer("Chris", 1, 1), NewPlayer("Cleo", 1, 1)})
+	})
+
+	t.Run("season queries see new wins", func(t *testing.T) {
+		store, _ := factory(t)
+		mustStartSeason(t, store, "2026-Q3")
+		mustRecordWins(t, store, "Cleo", 1)
+		mustQueryLeague(t, store, LeagueQuery{Season: "2026-Q3", Sort: "name"})
+		mustRecordWins(t, store, "Chris", 1)
+
+		page := mustQueryLeague(t, store, LeagueQuery{Season: "2026-Q3", Sort: "name"})
+		AssertLeague(t, page.Players, []Player{NewPlayer("Chris", 1, 1), NewPlayer("Cleo", 1, 1)})
+
+		league, err := store.GetSeasonLeague("2026-Q3")
+		if err != nil {
+			t.Fatalf("didn't expect error getting season league, got %v", err)
+		}
+		AssertLeague(t, league, []Player{NewPlayer("Chris", 1, 1), NewPlayer("Cleo", 1, 1)})
+	})
+
+	t.Run("refuses queries it can't answer", func(t *testing.T) {
+		store, _ := factory(t)
+		mustRecordWins(t, store, "Cleo", 1)
+
+		for _, query := range []LeagueQuery{
+			{Sort: "height"},
+			{Order: "up"},
+			{Cursor: "nobody"},
+			{Cursor: encodeLeagueCursor("Cleo"), Offset: 1},
+		} {
+			if _, err := store.QueryLeague(query); !errors.Is(err, ErrBadLeagueQuery) {
+				t.Errorf("got error %v for %+v, wanted %v", err, query, ErrBadLeagueQuery)
+			}
+		}
+		if _, err := store.QueryLeague(LeagueQuery{Season: "1999-Q1"}); !errors.Is(err, ErrUnknownSeason) {
+			t.Errorf("got error %v for an unknown season, wanted %v", err, ErrUnknownSeason)
+		}
+	})
+
+	t.Run("wins survive reopening the store", func(t *testing.T) {
+		store, reopen := factory(t)
+		if reopen == nil {
+			t.Skip("store does not persist")
+		}
+
+		mustRecordWins(t, store, "Cleo", 1)
+		mustRecordWins(t, store, "Chris", 2)
+		games := store.GetGames()
+
+		store = reopen()
+
+		if got := store.GetGames(); !reflect.DeepEqual(got, games) {
+			t.Errorf("got games %v after reopening, wanted %v", got, games)
+		}
+
+		assertContractScore(t, store, "Chris", 2)
+		AssertLeague(t, store.GetLeague(), []Player{
+			NewPlayer("Chris", 2, 2),
+			NewPlayer("Cleo", 1, 1),
+		})
+	})
+
+	t.Run("player changes survive reopening the store", func(t *testing.T) {
+		store, reopen := factory(t)
+		if reopen == nil {
+			t.Skip("store does not persist")
+		}
+
+		mustRecordWins(t, store, "Chirs", 1)
+		mustRecordWins(t, store, "Chris", 1)
+		if err := store.RecordGame(GameResult{Players: []string{"Chris", "Pepper"}, Winner: "Chris"}); err != nil {
+			t.Fatalf("didn't expect error recording game, got %v", err)
+		}
+		if err := store.RegisterPlayer("Ruth"); err != nil {
+			t.Fatalf("didn't expect error registering player, got %v", err)
+		}
+		if err := store.MergePlayers("Chirs", "Chris"); err != nil {
+			t.Fatalf("didn't expect error merging players, got %v", err)
+		}
+		if err := store.DeletePlayer("Pepper"); err != nil {
+			t.Fatalf("didn't expect error deleting player, got %v", err)
+		}
+
+		store = reopen()
+
+		AssertLeague(t, store.GetLeague(), []Player{NewPlayer("Chris", 3, 3)})
+		if got, want := store.GetRoster(), []string{"Chris", "Ruth"}; !reflect.DeepEqual(got, want) {
+			t.Errorf("got roster %v after reopening, wanted %v", got, want)
+		}
+	})
+
+	t.Run("seasons survive reopening the store", func(t *testing.T) {
+		store, reopen := factory(t)
+		if reopen == nil {
+			t.Skip("store does not persist")
+		}
+
+		mustStartSeason(t, store, "2026-Q3")
+		mustRecordWins(t, store, "Cleo", 1)
+		mustStartSeason(t, store, "2026-Q4")
+		seasons := store.GetSeasons()
+
+		store = reopen()
+
+		if got := store.GetSeasons(); !reflect.DeepEqual(got, seasons) {
+			t.Errorf("got seasons %v after reopening, wanted %v", got, seasons)
+		}
+		mustRecordWins(t, store, "Chris", 1)
+		if season := store.GetGames()[1].Season; season != "2026-Q4" {
+			t.Errorf("expected game to be in season 2026-Q4, got %q", season)
+		}
+	})
+}
+
+func mustStartSeason(t *testing.T, store PlayerStore, id string) {
+	t.Helper()
+	if err := store.StartSeason(id); err != nil {
+		t.Fatalf("didn't expect error starting season %s, got %v", id, err)
+	}
+}
+
+func mustQueryLeague(t *testing.T, store PlayerStore, query LeagueQuery) LeaguePage {
+	t.Helper()
+	page, err := store.QueryLeague(query)
+	if err != nil {
+		t.Fatalf("didn't expect error querying league with %+v, got %v", query, err)
 	}
+	return page
 }
 
-func retryUntil(d time.Duration, f func() bool) bool {
-	deadline := time.Now().Add(d)
-	for time.Now().Before(deadline) {
-		if f() {
-			return true
+func mustRecordWins(t *testing.T, store PlayerStore, name string, wins int) {
+	t.Helper()
+	for i := 0; i < wins; i++ {
+		if err := store.RecordWin(name); err != nil {
+			t.Fatalf("didn't expect error recording win for %s, got %v", name, err)
 		}
 	}
-	return false
+}
+
+func assertContractScore(t *testing.T, store PlayerStore, name string, want int) {
+	t.Helper()
+	if got, _ := store.GetPlayer(name); got.Wins != want {
+		t.Errorf("got score %d for %s, wanted %d", got.Wins, name, want)
+	}
+}
+
+// AssertGameResult checks got matches want, ignoring the ID the store gave it.
+func AssertGameResult(t testing.TB, got, want GameResult) {
+	t.Helper()
+	if got.ID == "" {
+		t.Errorf("expected game to be given an ID, got %+v", got)
+	}
+	got.ID = want.ID
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got game %+v, wanted %+v", got, want)
+	}
 }
@@ -1,34 +1,123 @@
 package poker
 
 import (
+	"context"
 	"io"
+	"sync"
 	"time"
 )
 
 type TexasHoldem struct {
 	alerter BlindAlerter
-	store   PlayerStore
+	store   GameStore
+
+	mu    sync.Mutex
+	clock Clock
 }
 
-func NewTexasHoldem(alerter BlindAlerter, store PlayerStore) *TexasHoldem {
+func NewTexasHoldem(alerter BlindAlerter, store GameStore) *TexasHoldem {
 	return &TexasHoldem{
 		alerter: alerter,
 		store:   store,
+		clock:   RealClock,
 	}
 }
 
-func (g *TexasHoldem) Start(numberOfPlayers int, alertsDestination io.Writer) {
-	blinds := []int{100, 200, 300, 400, 500, 600, 800, 1000, 2000, 4000, 8000}
+// UseClock times games with clock instead of the RealClock. The alerter
+// should use it too.
+func (g *TexasHoldem) UseClock(clock Clock) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.clock = clock
+}
 
-	blindIncrement := time.Duration(5+numberOfPlayers) * time.Minute
+// Start starts the clock on the blinds of a new game, returning the game so
+// its clock can be adjusted and it can be finished. Naming the players
+// records in the store that they took part as soon as the game starts, so it
+// counts as a game played for them even if it is abandoned. Each game keeps
+// its own result, so any number can be played at once.
+func (g *TexasHoldem) Start(ctx context.Context, numberOfPlayers int, blinds BlindStructure, alertsDestination io.Writer, players ...string) (Tournament, error) {
+	if len(blinds.Levels) == 0 {
+		blinds = DefaultBlindStructure(numberOfPlayers)
+	}
 
-	blindTime := 0 * time.Second
-	for _, blind := range blinds {
-		g.alerter.ScheduleAlertAt(blindTime, blind, alertsDestination)
-		blindTime = blindTime + blindIncrement
+	g.mu.Lock()
+	clock := g.clock
+	g.mu.Unlock()
+
+	game := &texasHoldemGame{
+		store:           g.store,
+		clock:           clock,
+		startedAt:       clock.Now().UTC(),
+		numberOfPlayers: numberOfPlayers,
+		players:         players,
+	}
+	if len(players) > 0 {
+		started, err := g.store.StartGame(GameResult{
+			StartedAt:       game.startedAt,
+			NumberOfPlayers: numberOfPlayers,
+			Players:         players,
+		})
+		if err != nil {
+			return nil, err
+		}
+		game.id = started.ID
 	}
+
+	ctx, game.cancel = context.WithCancel(ctx)
+	game.BlindClock = NewBlindClock(ctx, clock, g.alerter, blinds, alertsDestination)
+	return game, nil
 }
 
-func (g *TexasHoldem) Finish(winner string) {
-	g.store.RecordWin(winner)
+// texasHoldemGame is a game of TexasHoldem being played.
+type texasHoldemGame struct {
+	*BlindClock
+	store GameStore
+	clock Clock
+	// cancel stops the game's blind alerts.
+	cancel context.CancelFunc
+
+	// id is the ID the game was recorded with when it started, if it was.
+	id              string
+	startedAt       time.Time
+	numberOfPlayers int
+	players         []string
+
+	mu       sync.Mutex
+	finished bool
+}
+
+// Finish records the result of the game. runnersUp are the other players in
+// the order they finished, if known. Once it is recorded the game's blind
+// alerts are cancelled, and it can't be finished again.
+func (g *texasHoldemGame) Finish(winner string, runnersUp ...string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.finished {
+		return ErrGameOver
+	}
+
+	result := GameResult{
+		ID:              g.id,
+		StartedAt:       g.startedAt,
+		FinishedAt:      g.clock.Now().UTC(),
+		NumberOfPlayers: g.numberOfPlayers,
+		FinishingOrder:  append([]string{winner}, runnersUp...),
+		Winner:          winner,
+		FinalBlind:      g.Status().Blinds.SmallBlind,
+	}
+	switch {
+	case len(g.players) > 0:
+		result.Players = g.players
+	case len(runnersUp) > 0:
+		result.Players = result.FinishingOrder
+	}
+
+	if err := g.store.RecordGame(result); err != nil {
+		return err
+	}
+	g.finished = true
+	g.cancel()
+	return nil
 }
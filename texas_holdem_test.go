@@ -1,7 +1,12 @@
 package poker_test
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
 	"io/ioutil"
+	"reflect"
 	"testing"
 	"time"
 
@@ -14,7 +19,7 @@ func TestGame_Start(t *testing.T) {
 		blindAlerter := &poker.SpyBlindAlerter{}
 		game := poker.NewTexasHoldem(blindAlerter, dummyPlayerStore)
 
-		game.Start(5, ioutil.Discard)
+		mustStart(t, game, 5, poker.BlindStructure{}, ioutil.Discard)
 
 		cases := []poker.ScheduledAlert{
 			{At: 0 * time.Second, Amount: 100},
@@ -37,7 +42,7 @@ func TestGame_Start(t *testing.T) {
 		blindAlerter := &poker.SpyBlindAlerter{}
 		game := poker.NewTexasHoldem(blindAlerter, dummyPlayerStore)
 
-		game.Start(7, ioutil.Discard)
+		mustStart(t, game, 7, poker.BlindStructure{}, ioutil.Discard)
 
 		cases := []poker.ScheduledAlert{
 			{At: 0 * time.Second, Amount: 100},
@@ -47,6 +52,29 @@ func TestGame_Start(t *testing.T) {
 		}
 		poker.CheckSchedulingCases(cases, t, blindAlerter)
 	})
+
+	t.Run("it schedules alerts for each level of the blind structure", func(t *testing.T) {
+		blindAlerter := &poker.SpyBlindAlerter{}
+		game := poker.NewTexasHoldem(blindAlerter, dummyPlayerStore)
+
+		turbo := poker.BlindStructure{Name: "turbo", Levels: []poker.BlindLevel{
+			{SmallBlind: 25, BigBlind: 50, Duration: poker.Duration(3 * time.Minute)},
+			{SmallBlind: 50, BigBlind: 100, Ante: 10, Duration: poker.Duration(5 * time.Minute)},
+			{SmallBlind: 100, BigBlind: 200, Ante: 25},
+		}}
+		mustStart(t, game, 5, turbo, ioutil.Discard)
+
+		cases := []poker.ScheduledAlert{
+			{At: 0 * time.Second, Amount: 25},
+			{At: 3 * time.Minute, Amount: 50},
+			{At: 8 * time.Minute, Amount: 100},
+		}
+		poker.CheckSchedulingCases(cases, t, blindAlerter)
+
+		if got := blindAlerter.Alerts(); len(got) != 3 || got[2].Level != turbo.Levels[2] {
+			t.Errorf("got alerts %v, wanted one for each of %v", got, turbo.Levels)
+		}
+	})
 }
 
 func TestGame_Finish(t *testing.T) {
@@ -55,6 +83,225 @@ func TestGame_Finish(t *testing.T) {
 	game := poker.NewTexasHoldem(dummyBlindAlerter, store)
 
 	winner := "Ruth"
-	game.Finish(winner)
+	mustStart(t, game, 5, poker.BlindStructure{}, ioutil.Discard).Finish(winner)
 	poker.AssertPlayerWin(t, store, winner)
+
+	t.Run("it records the result of the game", func(t *testing.T) {
+		store := &poker.StubPlayerStore{}
+		startedAt := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+		clock := poker.NewFakeClock(startedAt)
+		game := poker.NewTexasHoldem(poker.AlerterOn(clock), store)
+		game.UseClock(clock)
+
+		played := mustStart(t, game, 3, poker.BlindStructure{}, ioutil.Discard)
+		clock.Advance(20 * time.Minute)
+		err := played.Finish("Ruth", "Cleo", "Chris")
+		assertNoError(t, err)
+
+		if len(store.Games) != 1 {
+			t.Fatalf("expected one game to be recorded, got %v", store.Games)
+		}
+		want := poker.GameResult{
+			StartedAt:       startedAt,
+			FinishedAt:      startedAt.Add(20 * time.Minute),
+			NumberOfPlayers: 3,
+			Players:         []string{"Ruth", "Cleo", "Chris"},
+			FinishingOrder:  []string{"Ruth", "Cleo", "Chris"},
+			Winner:          "Ruth",
+			FinalBlind:      300,
+		}
+		if got := store.Games[0]; !reflect.DeepEqual(got, want) {
+			t.Errorf("got game %+v, wanted %+v", got, want)
+		}
+	})
+
+	t.Run("it records everyone named as playing as soon as it starts", func(t *testing.T) {
+		store := &poker.StubPlayerStore{}
+		game := poker.NewTexasHoldem(dummyBlindAlerter, store)
+		want := []string{"Ruth", "Cleo", "Chris"}
+
+		played := mustStart(t, game, 3, poker.BlindStructure{}, ioutil.Discard, "Ruth", "Cleo", "Chris")
+		if len(store.Started) != 1 || !reflect.DeepEqual(store.Started[0].Players, want) {
+			t.Fatalf("got started games %+v, wanted one played by %v", store.Started, want)
+		}
+
+		err := played.Finish("Cleo")
+		assertNoError(t, err)
+
+		got := store.Games[0]
+		if !reflect.DeepEqual(got.Players, want) {
+			t.Errorf("got players %v, wanted %v", got.Players, want)
+		}
+		if got.ID != store.Started[0].ID {
+			t.Errorf("got game %q finished, wanted the started game %q", got.ID, store.Started[0].ID)
+		}
+	})
+
+	t.Run("it can't be finished twice", func(t *testing.T) {
+		store := &poker.StubPlayerStore{}
+		game := poker.NewTexasHoldem(dummyBlindAlerter, store)
+
+		played := mustStart(t, game, 3, poker.BlindStructure{}, ioutil.Discard)
+		assertNoError(t, played.Finish("Ruth"))
+
+		if err := played.Finish("Cleo"); !errors.Is(err, poker.ErrGameOver) {
+			t.Errorf("got error %v finishing again, wanted %v", err, poker.ErrGameOver)
+		}
+		if len(store.Games) != 1 {
+			t.Errorf("expected one game to be recorded, got %v", store.Games)
+		}
+	})
+
+	t.Run("games started together keep their own results", func(t *testing.T) {
+		store := &poker.StubPlayerStore{}
+		startedAt := time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC)
+		clock := poker.NewFakeClock(startedAt)
+		game := poker.NewTexasHoldem(poker.AlerterOn(clock), store)
+		game.UseClock(clock)
+
+		firstAlerts, secondAlerts := &bytes.Buffer{}, &bytes.Buffer{}
+		first := mustStart(t, game, 3, poker.BlindStructure{}, firstAlerts, "Ruth", "Cleo", "Chris")
+		clock.Advance(10 * time.Minute)
+		second := mustStart(t, game, 2, poker.BlindStructure{}, secondAlerts, "Pepper", "Apollo")
+		clock.Advance(0)
+		assertAlerted(t, firstAlerts, "blinds are now 100/200\nblinds are now 200/400\n")
+		assertAlerted(t, secondAlerts, "blinds are now 100/200\n")
+
+		assertNoError(t, first.Finish("Cleo"))
+		clock.Advance(7 * time.Minute)
+		assertAlerted(t, firstAlerts, "")
+		assertAlerted(t, secondAlerts, "blinds are now 200/400\n")
+
+		want := poker.GameResult{
+			ID:              store.Started[0].ID,
+			StartedAt:       startedAt,
+			FinishedAt:      startedAt.Add(10 * time.Minute),
+			NumberOfPlayers: 3,
+			Players:         []string{"Ruth", "Cleo", "Chris"},
+			FinishingOrder:  []string{"Cleo"},
+			Winner:          "Cleo",
+			FinalBlind:      200,
+		}
+		if len(store.Games) != 1 || !reflect.DeepEqual(store.Games[0], want) {
+			t.Errorf("got games %+v, wanted %+v", store.Games, want)
+		}
+		assertNoError(t, second.Finish("Apollo"))
+	})
+}
+
+func TestGame_Alerts(t *testing.T) {
+	clock := poker.NewFakeClock(time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC))
+	game := poker.NewTexasHoldem(poker.AlerterOn(clock), &poker.StubPlayerStore{})
+	game.UseClock(clock)
+	out := &bytes.Buffer{}
+
+	played := mustStart(t, game, 5, poker.BlindStructure{}, out)
+	clock.Advance(0)
+	assertAlerted(t, out, "blinds are now 100/200\n")
+
+	clock.Advance(10*time.Minute - time.Second)
+	assertAlerted(t, out, "")
+	clock.Advance(time.Second)
+	assertAlerted(t, out, "blinds are now 200/400\n")
+
+	clock.Advance(25 * time.Minute)
+	assertAlerted(t, out, "blinds are now 300/600\nblinds are now 400/800\n")
+
+	assertNoError(t, played.Finish("Ruth"))
+	clock.Advance(time.Hour)
+	assertAlerted(t, out, "")
+}
+
+func TestGame_BreaksAndWarnings(t *testing.T) {
+	clock := poker.NewFakeClock(time.Date(2026, 10, 18, 20, 0, 0, 0, time.UTC))
+	game := poker.NewTexasHoldem(poker.AlerterOn(clock), &poker.StubPlayerStore{})
+	game.UseClock(clock)
+	out := &bytes.Buffer{}
+
+	blinds := poker.BlindStructure{
+		Levels: []poker.BlindLevel{
+			{SmallBlind: 100, BigBlind: 200, Duration: poker.Duration(10 * time.Minute)},
+			{SmallBlind: 200, BigBlind: 400, Duration: poker.Duration(10 * time.Minute)},
+			{SmallBlind: 400, BigBlind: 800},
+		},
+		Breaks:  &poker.Breaks{Every: 2, Duration: poker.Duration(5 * time.Minute), Notices: []string{"colour up the 25 chips"}},
+		Warning: poker.Duration(time.Minute),
+	}
+	mustStart(t, game, 3, blinds, out)
+	clock.Advance(0)
+	assertAlerted(t, out, "blinds are now 100/200\n")
+
+	clock.Advance(9 * time.Minute)
+	assertAlerted(t, out, "1 minute until blinds go to 200/400\n")
+	clock.Advance(time.Minute)
+	assertAlerted(t, out, "blinds are now 200/400\n")
+
+	clock.Advance(10 * time.Minute)
+	assertAlerted(t, out, "break for 5 minutes: colour up the 25 chips\n")
+	clock.Advance(5 * time.Minute)
+	assertAlerted(t, out, "1 minute until blinds go to 400/800\nblinds are now 400/800\n")
+}
+
+// mustStart starts a game, failing the test if it can't be.
+func mustStart(t testing.TB, game poker.Game, numberOfPlayers int, blinds poker.BlindStructure, out io.Writer, players ...string) poker.Tournament {
+	t.Helper()
+	played, err := game.Start(context.Background(), numberOfPlayers, blinds, out, players...)
+	if err != nil {
+		t.Fatalf("problem starting game, %v", err)
+	}
+	return played
+}
+
+// assertAlerted checks the alerts written since it was last called.
+func assertAlerted(t testing.TB, out *bytes.Buffer, want string) {
+	t.Helper()
+	if got := out.String(); got != want {
+		t.Errorf("got alerts %q, wanted %q", got, want)
+	}
+	out.Reset()
+}
+
+func TestGame_CancelsAlerts(t *testing.T) {
+	t.Run("it cancels the alerts once the game finishes", func(t *testing.T) {
+		blindAlerter := &poker.SpyBlindAlerter{}
+		game := poker.NewTexasHoldem(blindAlerter, &poker.StubPlayerStore{})
+
+		played := mustStart(t, game, 3, poker.BlindStructure{}, ioutil.Discard)
+		if blindAlerter.Cancelled() {
+			t.Fatal("alerts were cancelled before the game finished")
+		}
+
+		assertNoError(t, played.Finish("Ruth"))
+		if !blindAlerter.Cancelled() {
+			t.Error("alerts were not cancelled when the game finished")
+		}
+	})
+
+	t.Run("it cancels the alerts when the game is abandoned", func(t *testing.T) {
+		blindAlerter := &poker.SpyBlindAlerter{}
+		game := poker.NewTexasHoldem(blindAlerter, &poker.StubPlayerStore{})
+
+		ctx, abandon := context.WithCancel(context.Background())
+		_, err := game.Start(ctx, 3, poker.BlindStructure{}, ioutil.Discard)
+		assertNoError(t, err)
+		abandon()
+
+		if !blindAlerter.Cancelled() {
+			t.Error("alerts were not cancelled when the game was abandoned")
+		}
+	})
+
+	t.Run("it keeps the alerts going when the result can't be recorded", func(t *testing.T) {
+		blindAlerter := &poker.SpyBlindAlerter{}
+		game := poker.NewTexasHoldem(blindAlerter, poker.NewRosterPlayerStore(&poker.StubPlayerStore{}))
+
+		played := mustStart(t, game, 3, poker.BlindStructure{}, ioutil.Discard)
+		if err := played.Finish("Ruth"); err == nil {
+			t.Fatal("expected an error recording a player who isn't on the roster")
+		}
+
+		if blindAlerter.Cancelled() {
+			t.Error("alerts were cancelled though the game hasn't finished")
+		}
+	})
 }
@@ -0,0 +1,31 @@
+package poker
+
+import "time"
+
+// Clock tells the time and runs functions once a while has passed. Games and
+// the server use the RealClock unless told otherwise, which lets tests use a
+// clock they move on themselves.
+type Clock interface {
+	Now() time.Time
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a function waiting to be run by a Clock.
+type Timer interface {
+	// Stop stops the function being run, reporting whether it was still
+	// waiting.
+	Stop() bool
+}
+
+// RealClock is the Clock on the wall.
+var RealClock Clock = realClock{}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}